@@ -27,19 +27,23 @@ type Enum[T any] struct {
 // Names must be unique for the same type, panics if the name already exists.
 // Enum values are stored in a global registry and persist even when created inside functions.
 func New[T any](name string, options ...Option) (e Enum[T]) {
-	e.init()
-	if err := e.setName(name); err != nil {
+	if err := e.initWith(name, options); err != nil {
 		panic(err)
 	}
-	for _, option := range options {
-		if err := option(&e); err != nil {
-			panic(err)
-		}
-	}
-	storeEnumer(e.typ, &e)
 	return
 }
 
+// Init initializes the enum value pointed to by ptr, which must be of type *Enum[T].
+// It behaves like New, but the type parameter is determined at runtime and errors are returned
+// instead of panicking. It's intended for packages creating enum values by reflection.
+func Init(ptr any, name string, options ...Option) error {
+	e, ok := ptr.(enumer)
+	if !ok {
+		return newEnumError("Init", fmt.Errorf(`invalid type "%T"`, ptr))
+	}
+	return e.initWith(name, options)
+}
+
 // IsValid returns whether the enum value is valid.
 func (e Enum[T]) IsValid() bool {
 	return e.enumConfig != nil
@@ -115,11 +119,29 @@ func (e Enum[T]) GoString() string {
 
 // MarshalText implements the encoding.TextMarshaler interface.
 // Returns the enum name, or [InvalidError] if invalid.
-func (e Enum[T]) MarshalText() ([]byte, error) { return e.MarshalJSON() }
+//
+// Compatibility note: earlier versions returned the JSON quoted name like MarshalJSON, e.g. "\"info\""
+// instead of "info", which [Enum.UnmarshalText] still accepts.
+func (e Enum[T]) MarshalText() ([]byte, error) {
+	if !e.IsValid() {
+		return nil, newInvalidError()
+	}
+	return []byte(e.name), nil
+}
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
-// The text should be a saved enum name for type T, returns [NameNotExistedError] if not found.
-func (e *Enum[T]) UnmarshalText(text []byte) error { return e.UnmarshalJSON(text) }
+// The text should be a saved enum name for type T, or the JSON quoted name returned by earlier versions
+// of [Enum.MarshalText], returns [NameNotExistedError] if not found.
+func (e *Enum[T]) UnmarshalText(text []byte) error {
+	name := string(text)
+	if len(text) >= 2 && text[0] == '"' {
+		var quoted string
+		if err := json.Unmarshal(text, &quoted); err == nil {
+			name = quoted
+		}
+	}
+	return e.unmarshalName(name)
+}
 
 // MarshalJSON implements the json.Marshaler interface.
 // Returns the enum name, or [InvalidError] if invalid.
@@ -137,23 +159,21 @@ func (e *Enum[T]) UnmarshalJSON(bytes []byte) error {
 	if err := json.Unmarshal(bytes, &name); err != nil {
 		return err
 	}
-
-	typ := reflectTypeString[T]()
-	enum, existed := loadEnumerByName(typ, name)
-	if !existed {
-		return newNameNotExistedError(typ, name)
-	}
-
-	copyEnum(e, enum)
-	return nil
+	return e.unmarshalName(name)
 }
 
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// Returns the gob encoded enum name, or [InvalidError] if invalid.
 func (e Enum[T]) MarshalBinary() ([]byte, error) { return e.GobEncode() }
 
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// The data should contain a gob encoded enum name for type T, returns [NameNotExistedError] if not found.
 func (e *Enum[T]) UnmarshalBinary(data []byte) error { return e.GobDecode(data) }
 
+// GobEncode implements the gob.GobEncoder interface.
+// Returns the gob encoded enum name, or [InvalidError] if invalid.
 func (e Enum[T]) GobEncode() ([]byte, error) {
-	if e.IsValid() {
+	if !e.IsValid() {
 		return nil, newInvalidError()
 	}
 
@@ -164,19 +184,14 @@ func (e Enum[T]) GobEncode() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// GobDecode implements the gob.GobDecoder interface.
+// The data should contain a gob encoded enum name for type T, returns [NameNotExistedError] if not found.
 func (e *Enum[T]) GobDecode(data []byte) error {
 	var name string
 	if err := gob.NewDecoder(bytes.NewBuffer(data)).Decode(&name); err != nil {
 		return err
 	}
-
-	enum, ok := GetEnumByName[T](name)
-	if !ok {
-		return newNameNotExistedError(reflectTypeString[T](), name)
-	}
-
-	copyEnum(e, &enum)
-	return nil
+	return e.unmarshalName(name)
 }
 
 // Option represents a configuration option for enum values.
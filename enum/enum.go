@@ -91,6 +91,7 @@ type enumer interface {
 	setNumber(number int) error
 	setValue(value any) error
 	setIgnoreCase(ignoreCase bool) error
+	initWith(name string, options []Option) error
 }
 
 func (e Enum[T]) isIgnoreCase() bool  { return e.ignoreCase }
@@ -137,10 +138,37 @@ func (e *Enum[T]) init() {
 	}
 }
 
+func (e *Enum[T]) initWith(name string, options []Option) error {
+	var enum Enum[T]
+	enum.init()
+	if err := enum.setName(name); err != nil {
+		return err
+	}
+	for _, option := range options {
+		if err := option(&enum); err != nil {
+			return err
+		}
+	}
+	storeEnumer(enum.typ, &enum)
+	*e = enum
+	return nil
+}
+
 func reflectTypeString[T any]() string {
 	return reflect.TypeOf((*T)(nil)).Elem().String()
 }
 
+func (e *Enum[T]) unmarshalName(name string) error {
+	typ := reflectTypeString[T]()
+	enum, existed := loadEnumerByName(typ, name)
+	if !existed {
+		return newNameNotExistedError(typ, name)
+	}
+
+	copyEnum(e, enum)
+	return nil
+}
+
 func copyEnum[T any](e *Enum[T], dst enumer) {
 	e.enumConfig = dst.config()
 	e.name = dst.Name()
@@ -397,6 +397,18 @@ func TestEnum_UnmarshalText(t *testing.T) {
 			text:   "invalid",
 			hasErr: true,
 		},
+		4: {
+			text:   `"warn"`,
+			wanted: warnLevel,
+		},
+		5: {
+			text:   `"invalid"`,
+			hasErr: true,
+		},
+		6: {
+			text:   `"info`,
+			hasErr: true,
+		},
 	}
 
 	for i, test := range tests {
@@ -502,3 +514,31 @@ func TestEnum_UnmarshalJSON(t *testing.T) {
 		}
 	}
 }
+
+type initLevel string
+
+func TestInit(t *testing.T) {
+	t.Run("enum pointer", func(t *testing.T) {
+		var e Enum[initLevel]
+		if err := Init(&e, "trace", WithNumber(-1)); err != nil {
+			t.Fatalf("got error %v, want no error", err)
+		}
+		if got, ok := GetEnumByName[initLevel]("trace"); !ok || got != e || got.Number() != -1 {
+			t.Errorf("got: %#v, want: %#v", got, e)
+		}
+	})
+
+	t.Run("existed name", func(t *testing.T) {
+		var e Enum[initLevel]
+		if err := Init(&e, "trace"); err == nil {
+			t.Errorf("got: %#v, want error", e)
+		}
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		var e Enum[initLevel]
+		if err := Init(e, "invalid"); err == nil {
+			t.Errorf("got: no error, want error")
+		}
+	})
+}
@@ -0,0 +1,165 @@
+// Package enumtest provides reusable test helpers for types registered with package enum.
+// It checks that every registered enum value survives a round trip through all encodings
+// supported by [enum.Enum], and provides fuzz targets for the decoding methods.
+package enumtest
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+
+	"github.com/QAQandOwO/godget/enum"
+)
+
+// codec describes an encoding supported by enum.Enum.
+type codec[T any] struct {
+	name      string
+	marshal   func(e enum.Enum[T]) ([]byte, error)
+	unmarshal func(e *enum.Enum[T], data []byte) error
+}
+
+func codecs[T any]() []codec[T] {
+	return []codec[T]{
+		{
+			name:      "json",
+			marshal:   func(e enum.Enum[T]) ([]byte, error) { return json.Marshal(e) },
+			unmarshal: func(e *enum.Enum[T], data []byte) error { return json.Unmarshal(data, e) },
+		},
+		{
+			name:      "text",
+			marshal:   enum.Enum[T].MarshalText,
+			unmarshal: (*enum.Enum[T]).UnmarshalText,
+		},
+		{
+			name:      "binary",
+			marshal:   enum.Enum[T].MarshalBinary,
+			unmarshal: (*enum.Enum[T]).UnmarshalBinary,
+		},
+		{
+			name: "gob",
+			marshal: func(e enum.Enum[T]) ([]byte, error) {
+				var buf bytes.Buffer
+				if err := gob.NewEncoder(&buf).Encode(e); err != nil {
+					return nil, err
+				}
+				return buf.Bytes(), nil
+			},
+			unmarshal: func(e *enum.Enum[T], data []byte) error {
+				return gob.NewDecoder(bytes.NewReader(data)).Decode(e)
+			},
+		},
+	}
+}
+
+// RoundTrip checks that every registered enum value of type T can be encoded and decoded
+// through JSON, text, binary and gob, and that the decoded value is identical to the original.
+// It also checks that encoding an invalid enum value returns [enum.InvalidError].
+// RoundTrip fails the test if no enum value of type T is registered.
+func RoundTrip[T any](t *testing.T) {
+	t.Helper()
+
+	enums, ok := enum.GetEnums[T]()
+	if !ok || len(enums) == 0 {
+		t.Fatalf("no registered enum values for %T", enum.Enum[T]{})
+	}
+
+	for _, c := range codecs[T]() {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			for _, e := range enums {
+				data, err := c.marshal(e)
+				if err != nil {
+					t.Errorf("marshal %q: got error %v, want no error", e.Name(), err)
+					continue
+				}
+
+				var got enum.Enum[T]
+				if err = c.unmarshal(&got, data); err != nil {
+					t.Errorf("unmarshal %q from %q: got error %v, want no error", e.Name(), data, err)
+				} else if got != e {
+					t.Errorf("unmarshal %q from %q: got %#v, want %#v", e.Name(), data, got, e)
+				}
+			}
+
+			if data, err := c.marshal(enum.Enum[T]{}); err == nil {
+				t.Errorf("marshal invalid enum: got %q, want error", data)
+			}
+		})
+	}
+}
+
+// FuzzUnmarshalJSON runs a fuzz target against [enum.Enum.UnmarshalJSON] for type T.
+// The corpus is seeded with the JSON encoding of every registered enum value.
+// Decoding must never panic, and a successfully decoded value must be valid
+// and encode back to a name accepted by UnmarshalJSON.
+func FuzzUnmarshalJSON[T any](f *testing.F) {
+	f.Helper()
+
+	enums, _ := enum.GetEnums[T]()
+	for _, e := range enums {
+		data, err := e.MarshalJSON()
+		if err != nil {
+			f.Fatalf("marshal %q: %v", e.Name(), err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte(`""`))
+	f.Add([]byte(`null`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var e enum.Enum[T]
+		if err := e.UnmarshalJSON(data); err != nil {
+			return
+		}
+		checkDecoded(t, e, data)
+	})
+}
+
+// FuzzUnmarshalText runs a fuzz target against [enum.Enum.UnmarshalText] for type T.
+// The corpus is seeded with the bare and JSON quoted name of every registered enum value.
+// Decoding must never panic, and a successfully decoded value must be valid
+// and encode back to a name accepted by UnmarshalText.
+func FuzzUnmarshalText[T any](f *testing.F) {
+	f.Helper()
+
+	enums, _ := enum.GetEnums[T]()
+	for _, e := range enums {
+		quoted, _ := json.Marshal(e.Name())
+		f.Add(e.Name())
+		f.Add(string(quoted))
+	}
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, text string) {
+		var e enum.Enum[T]
+		if err := e.UnmarshalText([]byte(text)); err != nil {
+			return
+		}
+		checkDecoded(t, e, []byte(text))
+	})
+}
+
+func checkDecoded[T any](t *testing.T, e enum.Enum[T], input []byte) {
+	t.Helper()
+
+	if !e.IsValid() {
+		t.Fatalf("unmarshal %q: got invalid enum without error", input)
+	}
+	want, ok := enum.GetEnumByName[T](e.Name())
+	if !ok {
+		t.Fatalf("unmarshal %q: got unregistered name %q", input, e.Name())
+	}
+	if e != want {
+		t.Fatalf("unmarshal %q: got %#v, want %#v", input, e, want)
+	}
+
+	text, err := e.MarshalText()
+	if err != nil {
+		t.Fatalf("marshal %q: got error %v, want no error", e.Name(), err)
+	}
+	var again enum.Enum[T]
+	if err = again.UnmarshalText(text); err != nil || again != e {
+		t.Fatalf("round trip %q: got %#v, %v, want %#v", text, again, err, e)
+	}
+}
@@ -0,0 +1,29 @@
+package enumtest_test
+
+import (
+	"testing"
+
+	"github.com/QAQandOwO/godget/enum"
+	"github.com/QAQandOwO/godget/enum/enumtest"
+)
+
+type level string
+
+func init() {
+	enum.New[level]("info", enum.WithValue[level]("INFO"))
+	enum.New[level]("warn", enum.WithNumber(2))
+	enum.New[level]("error", enum.WithIgnoreCase(true))
+	enum.New[level]("debug", enum.WithNumber(5))
+}
+
+func TestRoundTrip(t *testing.T) {
+	enumtest.RoundTrip[level](t)
+}
+
+func FuzzUnmarshalJSON(f *testing.F) {
+	enumtest.FuzzUnmarshalJSON[level](f)
+}
+
+func FuzzUnmarshalText(f *testing.F) {
+	enumtest.FuzzUnmarshalText[level](f)
+}
@@ -2,6 +2,7 @@ package enum_test
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/QAQandOwO/godget/enum"
 	"sort"
@@ -287,14 +288,15 @@ func ExampleEnum_MarshalJSON() {
 	fmt.Println(string(bytes2), err2)
 	fmt.Println(string(bytes3), err3)
 	fmt.Println(string(bytes4), err4)
-	fmt.Println(string(bytes5), err5)
+	// the json.MarshalerError wrapping the error names the type differently between Go versions
+	fmt.Println(string(bytes5), errors.Unwrap(err5))
 
 	// Output:
 	// "zero" <nil>
 	// "other" <nil>
 	// "success" <nil>
 	// "other" <nil>
-	//  json: error calling MarshalJSON for type enum.Enum[struct {}]: invalid enum
+	//  invalid enum
 }
 
 func ExampleEnum_UnmarshalJSON() {
@@ -66,12 +66,12 @@ func ExampleNew() {
 		C float32
 	}]()
 
-	// Pay attention to floating-point errors
+	// Pay attention to floating-point errors, which are rounded by complex64 only with WithLenientAssignment
 	numEnums5 := fieldenum.New[struct {
 		A complex64 `fieldenum:"pow(1i, iota)"`
 		B complex64
 		C complex64
-	}]()
+	}](fieldenum.WithLenientAssignment())
 
 	printFieldEnum("strEnums", strEnums)
 	printFieldEnum("numEnums1", numEnums1)
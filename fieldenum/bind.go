@@ -0,0 +1,158 @@
+package fieldenum
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Binding binds the names of fields of type V of an enum struct to their values,
+// so that named types like "type Color int" can implement String, MarshalText and UnmarshalText methods:
+//
+//	type ColorEnum struct{ Red, Green, Blue Color }
+//
+//	var Colors = fieldenum.New[ColorEnum]()
+//	var colors = fieldenum.Bind[ColorEnum, Color](Colors)
+//
+//	func (c Color) String() string                { return colors.String(c) }
+//	func (c Color) MarshalText() ([]byte, error)  { return colors.MarshalText(c) }
+//	func (c *Color) UnmarshalText(b []byte) error { return colors.UnmarshalText(b, c) }
+//
+// A Binding is immutable and safe for concurrent use.
+type Binding[V comparable] struct {
+	typ     reflect.Type
+	names   []string
+	values  []V
+	index   map[string]int // index by name
+	byValue map[V]int      // index of the first field by value
+}
+
+// Bind returns the Binding of fields of type V of enums.
+// Only fields of type V are bound, fields of other types, groups and arrays are ignored.
+// If several fields have the same value, the value is named by the first one.
+//
+// Type T must be a struct or struct pointer type accepted by [New], and must have fields of type V,
+// otherwise it will panic.
+func Bind[T any, V comparable](enums T) *Binding[V] {
+	typ := reflect.TypeOf((*V)(nil)).Elem()
+	v, info, ok := structValue(enums)
+	if !ok {
+		panic(newFieldEnumError(fmt.Errorf(`invalid type "%s"`, reflect.TypeOf((*T)(nil)).Elem().String())))
+	}
+
+	b := &Binding[V]{typ: typ, index: make(map[string]int), byValue: make(map[V]int)}
+	for i, name := range info.names {
+		field := v.Field(i)
+		if field.Type() != typ {
+			continue
+		}
+		value := field.Interface().(V)
+		if _, ok := b.byValue[value]; !ok {
+			b.byValue[value] = len(b.values)
+		}
+		b.index[name] = len(b.values)
+		b.names, b.values = append(b.names, name), append(b.values, value)
+	}
+	if len(b.names) == 0 {
+		panic(newFieldEnumError(fmt.Errorf(`no field of type "%s"`, typ.String())))
+	}
+	return b
+}
+
+// Names returns the names of bound fields in declaration order.
+func (b *Binding[V]) Names() []string { return append([]string(nil), b.names...) }
+
+// Values returns the values of bound fields in declaration order.
+func (b *Binding[V]) Values() []V { return append([]V(nil), b.values...) }
+
+// Name returns the name of the first field whose value equals v.
+// If no field matches, it returns an empty string and false.
+func (b *Binding[V]) Name(v V) (string, bool) {
+	i, ok := b.byValue[v]
+	if !ok {
+		return "", false
+	}
+	return b.names[i], true
+}
+
+// Value returns the value of the field named name.
+// If no field is named name, it returns the zero value and false.
+func (b *Binding[V]) Value(name string) (V, bool) {
+	i, ok := b.index[name]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return b.values[i], true
+}
+
+// Validate returns an error if no field has the value v.
+func (b *Binding[V]) Validate(v V) error {
+	if _, ok := b.byValue[v]; !ok {
+		return fmt.Errorf(`invalid value "%s" of type "%s"`, b.format(v), b.typ.String())
+	}
+	return nil
+}
+
+// String returns the name of v, or the type name and the underlying value like "Color(5)"
+// if no field has the value v, so it can be used to implement the String method of V.
+func (b *Binding[V]) String(v V) string {
+	if name, ok := b.Name(v); ok {
+		return name
+	}
+	typeName := b.typ.Name()
+	if typeName == "" {
+		typeName = b.typ.String()
+	}
+	return typeName + "(" + b.format(v) + ")"
+}
+
+// Parse returns the value of the field named name, or an error if no field is named name.
+func (b *Binding[V]) Parse(name string) (V, error) {
+	v, ok := b.Value(name)
+	if !ok {
+		return v, fmt.Errorf(`invalid name "%s" of type "%s"`, name, b.typ.String())
+	}
+	return v, nil
+}
+
+// MarshalText returns the name of v, or an error if no field has the value v,
+// so it can be used to implement [encoding.TextMarshaler] by V.
+func (b *Binding[V]) MarshalText(v V) ([]byte, error) {
+	name, ok := b.Name(v)
+	if !ok {
+		return nil, b.Validate(v)
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText sets *v to the value of the field named text, or returns an error if no field is named text,
+// so it can be used to implement [encoding.TextUnmarshaler] by *V.
+func (b *Binding[V]) UnmarshalText(text []byte, v *V) error {
+	value, err := b.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*v = value
+	return nil
+}
+
+// format formats the underlying value of v without calling methods of V,
+// which may be implemented by the Binding itself.
+func (b *Binding[V]) format(v V) string {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits())
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(rv.Complex(), 'g', -1, rv.Type().Bits())
+	case reflect.String:
+		return rv.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
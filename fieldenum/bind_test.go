@@ -0,0 +1,117 @@
+package fieldenum
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type bindColor uint8
+
+type bindColors struct {
+	Red     bindColor `fieldenum:"1 << iota"`
+	Green   bindColor
+	Blue    bindColor
+	Crimson bindColor `fieldenum:"Red"`
+	Count   int       `fieldenum:"3"`
+}
+
+var (
+	bindColorEnum = New[bindColors]()
+	bindColorBind = Bind[bindColors, bindColor](bindColorEnum)
+)
+
+func (c bindColor) String() string                { return bindColorBind.String(c) }
+func (c bindColor) MarshalText() ([]byte, error)  { return bindColorBind.MarshalText(c) }
+func (c *bindColor) UnmarshalText(b []byte) error { return bindColorBind.UnmarshalText(b, c) }
+
+func TestBind(t *testing.T) {
+	b := bindColorBind
+	if got, want := b.Names(), []string{"Red", "Green", "Blue", "Crimson"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ERROR: got names %v, want %v", got, want)
+	}
+	if got, want := b.Values(), []bindColor{1, 2, 4, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ERROR: got values %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		value bindColor
+		name  string
+		ok    bool
+	}{
+		0: {value: 1, name: "Red", ok: true},
+		1: {value: 2, name: "Green", ok: true},
+		2: {value: 4, name: "Blue", ok: true},
+		3: {value: 3, ok: false},
+	}
+	for i, test := range tests {
+		if got, ok := b.Name(test.value); got != test.name || ok != test.ok {
+			t.Errorf("[%d]ERROR: got name %q %v, want %q %v", i, got, ok, test.name, test.ok)
+		}
+		if err := b.Validate(test.value); (err == nil) != test.ok {
+			t.Errorf("[%d]ERROR: got validation error %v, want ok %v", i, err, test.ok)
+		}
+	}
+
+	if got, ok := b.Value("Crimson"); got != 1 || !ok {
+		t.Errorf("ERROR: got value %v %v, want 1 true", got, ok)
+	}
+	if got, ok := b.Value("Count"); got != 0 || ok {
+		t.Errorf("ERROR: got value %v %v, want not found", got, ok)
+	}
+	if got := bindColor(4).String(); got != "Blue" {
+		t.Errorf("ERROR: got %q, want %q", got, "Blue")
+	}
+	if got := bindColor(5).String(); got != "bindColor(5)" {
+		t.Errorf("ERROR: got %q, want %q", got, "bindColor(5)")
+	}
+	if _, err := b.Parse("red"); err == nil || err.Error() != `invalid name "red" of type "fieldenum.bindColor"` {
+		t.Errorf("ERROR: got error %v, want invalid name", err)
+	}
+	if err := b.Validate(5); err == nil || err.Error() != `invalid value "5" of type "fieldenum.bindColor"` {
+		t.Errorf("ERROR: got error %v, want invalid value", err)
+	}
+}
+
+func TestBinding_text(t *testing.T) {
+	var config struct {
+		Colors []bindColor
+	}
+	if err := json.Unmarshal([]byte(`{"Colors": ["Blue", "Crimson"]}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	if want := []bindColor{4, 1}; !reflect.DeepEqual(config.Colors, want) {
+		t.Errorf("ERROR: got %v, want %v", config.Colors, want)
+	}
+	if got, err := json.Marshal(config); err != nil || string(got) != `{"Colors":["Blue","Red"]}` {
+		t.Errorf("ERROR: got %s %v, want %s", got, err, `{"Colors":["Blue","Red"]}`)
+	}
+
+	if err := json.Unmarshal([]byte(`{"Colors": ["Purple"]}`), &config); err == nil {
+		t.Errorf("ERROR: got no error, want error for invalid name")
+	}
+	if _, err := json.Marshal(bindColor(3)); err == nil {
+		t.Errorf("ERROR: got no error, want error for invalid value")
+	}
+}
+
+func TestBind_panic(t *testing.T) {
+	tests := []struct {
+		bind func()
+		want string
+	}{
+		0: {bind: func() { Bind[int, int](0) }, want: `fieldenum: invalid type "int"`},
+		1: {bind: func() { Bind[*bindColors, bindColor](nil) }, want: `fieldenum: invalid type "*fieldenum.bindColors"`},
+		2: {bind: func() { Bind[bindColors, string](bindColorEnum) }, want: `fieldenum: no field of type "string"`},
+	}
+	for i, test := range tests {
+		func() {
+			defer func() {
+				if err, ok := recover().(error); !ok || err.Error() != test.want {
+					t.Errorf("[%d]ERROR: got panic %v, want %s", i, err, test.want)
+				}
+			}()
+			test.bind()
+		}()
+	}
+}
@@ -35,11 +35,134 @@ Example for numeric enums:
 	}]()
 	// Result: {0 1 10 11 40 50}
 
+[New] evaluates on every call, so packages calling it for the same type get separate values.
+[Shared] evaluates once per type and options, safe for concurrent use, and returns copies,
+or pointers to copies for struct pointer types. Values assigned with [WithFuncs] or [WithFormatter]
+aren't cached, since functions can't be compared:
+
+	type Limits struct {
+		MaxBody int64 `fieldenum:"4 * MiB"`
+		MaxConn int   `fieldenum:"1 << 10"`
+	}
+
+	limits := fieldenum.Shared[*Limits](fieldenum.WithUnits()) // evaluated on the first call only
+
+Types with [enum.Enum] fields can't be shared, since their names are registered only once.
+
+# Lookup
+
+Field names and values can be looked up after assignment:
+
+	type Numbers struct {
+		Zero int
+		One  int
+		Ten  int `fieldenum:"10"`
+	}
+
+	var Number = fieldenum.New[Numbers]()
+
+	name, ok := fieldenum.NameOf(Number, 10)      // "Ten", true
+	value, ok := fieldenum.ValueOf(Number, "Ten") // 10, true
+	names := fieldenum.Names[Numbers]()           // [Zero One Ten]
+	values := fieldenum.Values(Number)            // [0 1 10]
+
+[Bind] binds the names of fields of a named type to their values, to implement
+String, MarshalText and UnmarshalText methods, so configs can decode "Red" into a Color:
+
+	type Color int
+
+	type ColorEnum struct {
+		Red   Color `fieldenum:"1 + iota"`
+		Green Color
+		Blue  Color
+	}
+
+	var Colors = fieldenum.New[ColorEnum]()
+	var colors = fieldenum.Bind[ColorEnum, Color](Colors)
+
+	func (c Color) String() string                { return colors.String(c) } // "Red", or "Color(5)"
+	func (c Color) MarshalText() ([]byte, error)  { return colors.MarshalText(c) }
+	func (c *Color) UnmarshalText(b []byte) error { return colors.UnmarshalText(b, c) }
+
+	v, err := colors.Parse("Green") // 2, nil
+	err = colors.Validate(Color(5)) // invalid value "5" of type "main.Color"
+
+# Errors
+
+[New] panics on the first call with invalid types or expressions,
+while [TryNew] returns the error instead. All fields are assigned before
+returning, and the error is [Errors] holding a [*FieldError] for every failing field:
+
+	_, err := fieldenum.TryNew[struct {
+		A int8 `fieldenum:"200"`
+		B int  `fieldenum:"1 + foo(2)"`
+	}]()
+	var fieldErr *fieldenum.FieldError
+	if errors.As(err, &fieldErr) {
+		// fieldErr.Name == "A", fieldErr.Err reports that 200 overflows int8
+	}
+
+A FieldError holds the field tag and the column range of its invalid part,
+and wraps [*ExprError], [*OpError] or [*FuncError] for invalid expressions.
+[FieldError.Diagnostic] renders it like compiler diagnostics:
+
+	struct{...}.B: call non-existed function "foo"
+		1 + foo(2)
+		    ^~~~~~
+
+[Check] checks a type without assigning or registering anything. The command
+fieldenumvet runs it on every fieldenum.New, TryNew, Shared and Explain call of packages,
+so invalid tags are reported at build time instead of panicking at startup:
+
+	go run github.com/QAQandOwO/godget/fieldenum/tools/cmd/fieldenumvet@latest ./...
+
+The same checks are provided as a [golang.org/x/tools/go/analysis] analyzer
+by package github.com/QAQandOwO/godget/fieldenum/tools/analyzer, which reports
+diagnostics on struct tags in editors and multi-analyzer drivers.
+The commands and the analyzer belong to the separate module github.com/QAQandOwO/godget/fieldenum/tools,
+so that importers of package fieldenum don't depend on golang.org/x/tools. The commands can be run
+with a version like above, or without it once the tools module is required by go.mod,
+and the go.work file of the repository links both modules for local development.
+
+# Explain
+
+[Explain] traces the assignment of every field, to find out how an unexpected value is computed.
+An [Explanation] holds the evaluated expression, rewritten for fields inheriting expressions,
+the value of iota, the value and the promoted type of every sub-expression, and the assigned value:
+
+	explanations, err := fieldenum.Explain[struct {
+		A int8 `fieldenum:"10"`
+		B int8
+	}]()
+	fmt.Println(explanations[1])
+	// B = (iota-0)+(10) with iota = 1
+	//	iota = 1 (int64)
+	//	0 = 0 (int64)
+	//	iota-0 = 1 (int64)
+	//	10 = 10 (int64)
+	//	(iota-0)+(10) = 11 (int64)
+	//	=> 11 (int8)
+
+# Code Generation
+
+The command fieldenumgen evaluates struct types with the same evaluator and writes
+typed const blocks and String methods, for code which avoids reflection at runtime:
+
+	//go:generate go run github.com/QAQandOwO/godget/fieldenum/tools/cmd/fieldenumgen@latest -type=Status -test
+	type Status struct {
+		OK       Code `fieldenum:"200"`
+		NotFound Code `fieldenum:"404"`
+	}
+	// Generated: const (StatusOK Code = 200; StatusNotFound Code = 404) and func (x Code) String() string
+
+With -test, a generated test checks that [New] and the generated constants agree.
+Struct types with group or array fields can't be generated.
+[NewValue] evaluates struct types known only at runtime, like the generator does.
+
 # Type Requirements
 
 Type T must meet the following conditions, otherwise it will panic:
   - Must be a struct or struct pointer type
-  - All struct fields must be of the same type
   - Fields must be exported
   - Field underlying types must be one of:
     int, int8, int16, int32, int64,
@@ -47,24 +170,134 @@ Type T must meet the following conditions, otherwise it will panic:
     float32, float64,
     complex64, complex128,
     string
+  - Named types like [time.Duration] are accepted by their underlying types
+  - Or field types must be [enum.Enum]
+  - Or field types must be structs meeting the same conditions, assigned as groups
+  - Or field types must be arrays of numeric types
 
 The types listed above except string are referred to as numeric types.
 
 # Assignment Rules
 
+Fields of different types can be mixed in one struct, each field is assigned
+by the rules of its own type. String fields are skipped by numeric expressions,
+but still count for iota:
+
+	var Limit = fieldenum.New[struct {
+		Code      int     `fieldenum:"100"`
+		Threshold float64 `fieldenum:"iota/4.0"`
+		Label     string  `fieldenum:"limit"`
+		Max       uint8   `fieldenum:"iota*10"`
+	}]()
+	// Result: {100 0.25 limit 30}
+
 1. String Types
   - If no fieldenum struct tag is present, field value is set to field name
-  - Otherwise use the value specified in the field tag
+  - Otherwise use the value specified in the field tag verbatim, tag options don't apply
+  - With [WithStringExpr], the field tag is evaluated as an expression instead,
+    see String Expressions below
 
 2. Numeric Types
   - Arithmetic expressions can be set via fieldenum struct tag
   - Arithmetic expressions follow Go syntax, panic on invalid syntax
   - If a field has fieldenum tag, subsequent fields reset to current expression
-  - iota placeholder can be used in expressions, representing current field index
+  - iota placeholder can be used in expressions, representing current field index,
+    which doesn't count fields skipped by tag options
+  - Names of previously assigned fields can be used in expressions, representing their values
   - Default assignment expression is "iota"
   - If expression contains "iota", no special processing is done
   - If expression doesn't contain "iota", subsequent fields increment from current result
   - Empty fieldenum tag is treated as "0"
+  - Results must fit the field type, otherwise it will panic:
+    out of range results, fractional parts, NaN and infinities for integer fields,
+    and integers or floats not exactly representable by float fields are rejected,
+    e.g. 0.1 for float32 and complex64 fields, which round it
+  - With [WithLenientAssignment], results are converted like Go conversions instead
+
+3. Enum Types
+  - Field value is registered by [enum.Init] with field name as the enum name
+  - Field names must be unique for the enum type, otherwise it will panic
+  - Fields are registered only after all fields are assigned without errors,
+    so a failing [TryNew] can be retried
+  - Enum number is assigned with the same rules as int fields
+
+Example for enum.Enum fields:
+
+	type Level struct{}
+
+	var Levels = fieldenum.New[struct {
+		Debug enum.Enum[Level] `fieldenum:"-1"`
+		Info  enum.Enum[Level]
+		Warn  enum.Enum[Level]
+	}]()
+	// Levels.Warn.Name() == "Warn", Levels.Warn.Number() == 1
+	// enum.GetEnumByName[Level]("Info") returns Levels.Info
+
+4. Group Types
+  - Struct fields group enums hierarchically, and their fields are assigned recursively
+  - The tag option "base=expr" of a group makes iota of its fields count from base,
+    so groups get separate value ranges like iota+base in Go constants
+  - The base of a nested group is relative to the base of the enclosing group
+  - Expressions without "iota" are not offset by base
+  - Fields of enclosing structs can be referenced, fields of a group shadow them
+  - Groups are skipped by numeric expressions, but still count for iota
+  - Errors of fields in groups are named by qualified names, e.g. "Auth.Expired"
+
+5. Array Types
+  - Arrays are numeric fields filled element-wise, iota is the element index
+  - Expressions without "iota" increment from the result per element
+  - Errors of elements are named by indexes, e.g. "Flags[2]"
+
+Example for group and array fields:
+
+	var Errors = fieldenum.New[struct {
+		Auth struct {
+			Expired int
+			Revoked int
+		} `fieldenum:"base=1000"`
+		DB struct {
+			Timeout int
+			Lost    int `fieldenum:"Timeout + 10"`
+		} `fieldenum:"base=2000"`
+		Flags [3]uint8 `fieldenum:"1 << iota"`
+	}]()
+	// Result: {{1000 1001} {2000 2010} [1 2 4]}
+
+# Tag Options
+
+A fieldenum tag of a numeric, array, group or enum field starting with one of the following options
+is a comma-separated list of options, otherwise the whole tag is the expression. Commas in parentheses
+and quoted strings don't separate options.
+  - "-": the field is left untouched, it doesn't count for iota and can't be referenced.
+    This is the only option for string fields too.
+  - expr=<expression>: the expression of the field
+  - skip: the field is assigned by its own expression, but it doesn't count for iota,
+    and subsequent fields continue the previous expression as if the field were absent
+  - reset: iota restarts at 0 from the field, an expression without iota restarts from its result
+  - step=<integer>: fields following an expression without iota increment by the step
+    instead of 1, without expr= the increment changes from the field
+  - base=<expression>: the base of group fields, see Group Types
+
+This mixes generated and manual fields in one struct:
+
+	var Code = fieldenum.New[struct {
+		OK       int `fieldenum:"expr=200,step=10"`
+		Created  int
+		Internal int `fieldenum:"expr=500,skip"`
+		Accepted int
+		Custom   int `fieldenum:"-"`
+		Retry    int `fieldenum:"reset"`
+	}]()
+	// Result: {200 210 500 220 0 200}
+
+Most of these tags used to be invalid expressions, but tag options change the meaning of some valid tags:
+  - The tags "skip" and "reset" of non-string fields used to be identifiers of values given by [WithValues],
+    now they are tag options. Rename such values, or write the expression in parentheses, e.g. "(skip)".
+  - The tag "-" of a string field used to be the value "-", now the field is left untouched.
+    Write the value as the expression "\"-\"" with [WithStringExpr] instead.
+
+Other tags of string fields are never options, since any tag used to be a valid string value,
+so string fields can't be skipped or reset by tag options.
 
 # Expression System
 
@@ -83,6 +316,32 @@ The expression system uses three numeric types:
 
 3. complex128: Used for complex operations
 
+4. bool: Used for comparison and logical operations
+  - Results of comparison operators and constants true and false
+  - Never promoted to or from numeric types, use int(x) or float(x) to convert
+  - Can't be assigned to numeric fields
+
+# Exact Arithmetic
+
+With [WithExactArithmetic], numeric expressions are evaluated exactly with [math/big]:
+  - Integers are evaluated as [big.Int], so they never overflow
+  - Floats are evaluated as [big.Rat], so 0.1 + 0.2 == 0.3 is true
+  - Integer division truncates like Go, division by zero is an error
+  - int, float, abs, max, min, pow, floor, ceil, round, trunc, gcd, lcm, bit and fact
+    are evaluated exactly, other functions and complex numbers are evaluated as usual
+  - The result must fit the field type exactly, otherwise it's an error:
+    integer fields reject results out of range or with a fractional part,
+    float fields reject results out of range and round fractions to float64 like Go constants,
+    but float32 and complex64 fields reject fractions they round further, e.g. 1/10.0
+
+For example, bitmasks over the int64 range can be assigned to uint64 fields:
+
+	var Mask = fieldenum.New[struct {
+		High uint64 `fieldenum:"1<<63"`
+		All  uint64 `fieldenum:"1<<64 - 1"`
+	}](fieldenum.WithExactArithmetic())
+	// Result: {9223372036854775808 18446744073709551615}
+
 # Literals
 
   - Integers: 123, 0xFF, 0b10101, 0123
@@ -97,13 +356,51 @@ The expression system uses three numeric types:
   - phi|Phi = math.Phi
   - nan|NaN = math.NaN()
   - inf|Inf = math.Inf(1)
+  - true, false = boolean constants
   - iota = current field index (int64)
 
+# Units
+
+With [WithUnits], unit identifiers are registered as int64 values:
+  - Sizes in bytes: KiB, MiB, GiB, TiB, PiB
+  - Durations in nanoseconds: ns, us|µs, ms, s, min, h
+
+Fields of type [time.Duration] are int64 fields, so durations are assigned like Go constants:
+
+	var Limit = fieldenum.New[struct {
+		Timeout time.Duration `fieldenum:"30*s"`
+		Session time.Duration `fieldenum:"2*h + 30*min"`
+		MaxBody int64         `fieldenum:"4*MiB"`
+	}](fieldenum.WithUnits())
+	// Result: {30s 2h30m0s 4194304}
+
+Units shadow fields of the same names, and min() is still the function.
+
+# Field References
+
+Names of previously assigned fields can be used as identifiers in expressions.
+Numeric fields are referenced by their assigned value, converted to int64, float64 or complex128,
+and enum.Enum fields by their number:
+
+	var Perm = fieldenum.New[struct {
+		Read      uint8 `fieldenum:"1<<iota"`
+		Write     uint8
+		Exec      uint8
+		ReadWrite uint8 `fieldenum:"Read|Write"`
+		All       uint8 `fieldenum:"ReadWrite|Exec"`
+	}]()
+	// Result: {1 2 4 3 7}
+
+Referencing the current field or a field declared after it is an error.
+Built-in constants and values registered by [WithValues] take precedence over field names.
+
 # Operators
 
 See package documentation for detailed operator tables covering:
-  - Unary: +, -, ^
+  - Unary: +, -, ^, !
   - Binary: +, -, *, /, %, &, |, ^, <<, >>, &^
+  - Comparison: ==, !=, <, <=, >, >=
+  - Logical: &&, ||
   - Type promotion and overflow handling rules
 
 Unary Operators:
@@ -122,6 +419,10 @@ Unary Operators:
   - int64: returns ^x
   - float64/complex128: panic
 
+4. !x (Logical NOT):
+  - bool: returns !x
+  - numeric: panic
+
 Binary Operators:
 
 1. x + y (Addition):
@@ -158,12 +459,69 @@ Binary Operators:
   - int64 &^ int64: int64
   - with float64/complex128: panic
 
+9. x == y, x != y (Equality):
+  - int64 == int64: compared as int64
+  - int64/float64 == float64: compared as float64
+  - numeric == complex128: compared as complex128
+  - bool == bool: compared as bool
+  - bool with numeric: panic
+
+10. x < y, x <= y, x > y, x >= y (Ordering):
+  - int64 < int64: compared as int64
+  - int64/float64 < float64: compared as float64
+  - with complex128/bool: panic
+
+11. x && y, x || y (Logical):
+  - bool && bool: bool
+  - y is not evaluated if the result is determined by x
+  - with numeric: panic
+
+# String Expressions
+
+Expressions of string fields are evaluated only when [WithStringExpr] is used:
+  - String literals: "abc", `abc`
+  - x + y: concatenation of two strings, with numeric: panic
+  - Comparison operators compare two strings lexically
+  - name = name of the current field (string)
+  - Fields without fieldenum tag evaluate the expression of the previous string field,
+    or are set to the field name if there is no previous one
+  - Empty fieldenum tag is treated as ""
+  - Numeric results are formatted in decimal by default, boolean results as "true" or "false"
+
+Example for string expressions:
+
+	var Status = fieldenum.New[struct {
+		StatusOK string `fieldenum:"\"status_\" + snake(name)"`
+		NotFound string
+		Code     int    `fieldenum:"404"`
+		Message  string `fieldenum:"fmt(\"%s: %d\", kebab(name), Code)"`
+	}](fieldenum.WithStringExpr())
+	// Result: {status_status_ok status_not_found 404 message: 404}
+
+Numeric results are formatted without locale, and their format can be set by options:
+  - [WithIntFormat]: fmt format of integers, e.g. "0x%04X" or "%b"
+  - [WithFloatFormat]: fmt format of floats and complex numbers, e.g. "%.3g" instead of
+    the long output of strconv.FormatFloat(x, 'f', -1, 64) for huge or tiny values
+  - [WithFormatter]: custom function formatting int64, float64 and complex128 values
+
+For example:
+
+	var Code = fieldenum.New[struct {
+		OK       string `fieldenum:"0x10 + iota"`
+		Redirect string
+	}](fieldenum.WithStringExpr(), fieldenum.WithIntFormat("0x%04X"))
+	// Result: {0x0010 0x0011}
+
 # Built-in Functions
 
   - Type conversion: int(), float(), complex(), real(), imag()
-  - Math: abs(), sqrt(), pow(), exp(), log(), log10()
-  - Trigonometry: sin(), cos(), tan(), asin(), acos(), atan(), sinh(), cosh(), tanh(), asinh(), acosh(), atanh()
-  - Aggregation: max(), min()
+  - Math: abs(), sqrt(), pow(), exp(), log(), log10(), log2(), hypot(), sign(), fact()
+  - Rounding: floor(), ceil(), round(), trunc()
+  - Integer and bit: gcd(), lcm(), bit(), popcount(), clz(), ctz()
+  - Trigonometry: sin(), cos(), tan(), asin(), acos(), atan(), atan2(), sinh(), cosh(), tanh(), asinh(), acosh(), atanh()
+  - Aggregation: max(), min(), clamp()
+  - Conditional: if()
+  - String: lower(), upper(), snake(), kebab(), fmt()
 
 Type Conversion Functions:
 
@@ -171,11 +529,13 @@ Type Conversion Functions:
   - int64: x
   - float64: int64(x)
   - complex128: int64(real(x))
+  - bool: 1 if x is true, 0 otherwise
 
 2. float(x): Converts to float64
   - int64: float64(x)
   - float64: x
   - complex128: real(x)
+  - bool: 1.0 if x is true, 0.0 otherwise
 
 3. complex(x[, y]): Converts to complex128
   - 1 arg: complex(x, 0). If type of x is complex128, return x.
@@ -219,12 +579,49 @@ Mathematical Functions:
   - int64/float64: math.Log10(x)
   - complex128: cmplx.Log10(x)
 
+7. log2(x): Base-2 logarithm, exact for powers of two
+  - int64/float64: math.Log2(x)
+  - complex128: cmplx.Log(x) / math.Ln2
+
+8. hypot(x, y): math.Hypot(x, y) of int64/float64 arguments
+
+9. sign(x): Sign of x
+  - int64: -1, 0 or 1
+  - float64: -1.0, 1.0, or x itself for zeros and NaN
+  - complex128: x / abs(x), or 0 if x is 0
+
+10. fact(n): Factorial, panics if n < 0
+  - int64 or float64 without fractional part: n! if in range, otherwise float64, e.g. fact(5.0) = 120
+  - float64: math.Gamma(n + 1)
+
+Rounding Functions:
+
+1. floor(x), ceil(x), round(x), trunc(x): corresponding math.* function
+  - int64: returns itself
+  - float64: float64, e.g. round(2.5) = 3.0, rounding half away from zero
+  - complex128: rounds the real and imaginary parts
+
+Integer and Bit Functions:
+
+1. gcd(x, y, ...), lcm(x, y, ...): Greatest common divisor and least common multiple
+  - Arguments must be int64 or float64 without fractional part, e.g. gcd(4.0, 6) = 2,
+    results are non-negative
+  - Results are float64 if out of range of int64
+
+2. bit(n): 1<<n, for bit-flag enums like "bit(iota)"
+  - Result is int64 if n < 63, otherwise float64 (exact for uint64 fields). Panics if n < 0
+
+3. popcount(x), clz(x), ctz(x): Number of one bits, leading zeros and trailing zeros
+  - x must be int64, counted on its 64-bit two's complement, e.g. clz(0) = 64
+
 Trigonometric Functions:
 
 1. sin, cos, tan, asin, acos, atan, sinh, cosh, tanh, asinh, acosh, atanh:
   - int64/float64: corresponding math.* function
   - complex128: corresponding cmplx.* function
 
+2. atan2(y, x): math.Atan2(y, x) of int64/float64 arguments
+
 Aggregation Functions:
 
 1. max(x, y, ...): Maximum value
@@ -235,6 +632,27 @@ Aggregation Functions:
 2. min(x, y, ...): Minimum value
   - Same rules as max()
 
+3. clamp(x, lo, hi): min(max(x, lo), hi)
+  - Same rules as max() and min()
+
+Conditional Functions:
+
+1. if(cond, x, y): Returns x if cond is true, otherwise y
+  - cond must be bool, otherwise panic
+  - Only the returned argument is evaluated
+  - Go has no ternary operator, so if is parsed as a function when followed by "("
+
+String Functions:
+
+1. lower(s), upper(s): Converts string s to lower or upper case
+
+2. snake(s), kebab(s): Converts string s to snake_case or kebab-case
+  - Words are split by non-alphanumeric characters and case changes
+  - e.g. snake("HTTPStatusOK") = "http_status_ok"
+
+3. fmt(pattern, args...): Formats args by pattern like fmt.Sprintf
+  - Numeric args are int64, float64 or complex128, e.g. fmt("0x%04X", iota)
+
 # Customization
 
 Use [WithValues] to register custom values:
@@ -245,10 +663,26 @@ Use [WithValues] to register custom values:
 
 Use [WithFuncs] to register custom functions:
 
-  - Function names must be unique
+  - Function names must be unique, and can't be names of built-in functions,
+    except functions added after WithFuncs, which registered functions shadow:
+    log2, hypot, atan2, floor, ceil, round, trunc, sign, clamp, gcd, lcm, fact,
+    bit, popcount, clz, ctz, lower, upper, snake, kebab and fmt
   - Recommended to handle int64, float64, complex128 types
 
-Example custom function:
+[Func1], [Func2] and [FuncVariadic] adapt typed Go functions, checking the number
+of arguments and converting arguments and results automatically:
+
+	fieldenum.WithFuncs(map[string]fieldenum.ExprFunc{
+		"floor": fieldenum.Func1[float64](math.Floor),
+		"atan2": fieldenum.Func2(math.Atan2),
+		"sum":   fieldenum.FuncVariadic[int](1, sum), // func sum(xs ...int) int
+	})
+
+Arguments are converted to the parameter types if they fit without precision loss,
+e.g. integers are promoted to floats, and 2.5 is rejected for int parameters.
+Rejected arguments are reported as [*FuncError] with the function name, Arg and the cause as Err.
+
+Example custom function handling arguments itself:
 
 	func add1(values []any) (any, error) {
 		if len(values) != 1 {
@@ -261,5 +695,29 @@ Example custom function:
 		default: return nil, errors.New("unsupported type")
 		}
 	}
+
+# Sandboxing
+
+Expressions from untrusted sources, e.g. struct tags of plugin-provided types,
+can be restricted by options:
+  - [WithMaxDepth]: maximum nesting depth of the syntax tree, e.g. the depth of "1 + 2*3" is 3
+  - [WithMaxNodes]: maximum number of nodes of the syntax tree, e.g. "max(1, 2)" has 3 nodes
+  - [WithAllowedFuncs]: names of allowed built-in and registered functions, if() is always allowed
+  - [WithContext]: stops evaluations once the context is done, e.g. on a deadline
+
+Depth and nodes are checked before evaluation, functions before calls,
+and the context before every binary operation and function call.
+Violations are reported as [*LimitError], whose Limit field tells the violated limit:
+
+	_, err := fieldenum.TryNew[Plugin](
+		fieldenum.WithMaxDepth(16),
+		fieldenum.WithMaxNodes(64),
+		fieldenum.WithAllowedFuncs("min", "max", "bit"),
+		fieldenum.WithContext(ctx),
+	)
+	var limitErr *fieldenum.LimitError
+	if errors.As(err, &limitErr) && limitErr.Limit == fieldenum.LimitContext {
+		// handle timeout, errors.Is(err, context.DeadlineExceeded) also works
+	}
 */
 package fieldenum
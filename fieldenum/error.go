@@ -1,11 +1,42 @@
 package fieldenum
 
 import (
+	"errors"
 	"fmt"
-	"go/token"
+	"reflect"
 	"strings"
+
+	"github.com/QAQandOwO/godget/fieldenum/internal/engine"
+)
+
+type (
+	// ExprError is an error of an expression in fieldenum struct tags.
+	// It wraps the cause, such as [*OpError] and [*FuncError],
+	// and its Columns method returns the column range of the invalid part of the evaluated expression.
+	ExprError = engine.ExprError
+	// OpError is an error of an operator applied on unsupported operands.
+	OpError = engine.OpError
+	// FuncError is an error of calling a function, which doesn't exist or is called on unsupported arguments.
+	FuncError = engine.FuncError
+	// LimitError is an error of an expression exceeding the limits set by [WithMaxDepth],
+	// [WithMaxNodes], [WithAllowedFuncs] and [WithContext].
+	LimitError = engine.LimitError
+)
+
+// Limits reported by [LimitError].
+const (
+	LimitDepth   = engine.LimitDepth   // nesting depth set by WithMaxDepth
+	LimitNodes   = engine.LimitNodes   // number of nodes set by WithMaxNodes
+	LimitFunc    = engine.LimitFunc    // function not allowed by WithAllowedFuncs
+	LimitContext = engine.LimitContext // context of WithContext is done
 )
 
+// errFailedField is the error of referencing a field which fails to be assigned.
+var errFailedField = errors.New("reference failed field")
+
+// errStepWithIota is the error of the tag option step for expressions with iota, which don't increment.
+var errStepWithIota = errors.New(`tag option "step" requires an expression without iota`)
+
 type fieldEnumError struct {
 	Err error
 }
@@ -14,130 +45,105 @@ func newFieldEnumError(err error) *fieldEnumError { return &fieldEnumError{Err:
 func (e *fieldEnumError) Unwrap() error           { return e.Err }
 func (e *fieldEnumError) Error() string           { return "fieldenum: " + e.Err.Error() }
 
-type fieldError struct {
-	Err  error
-	Name string
-}
-
-func newFieldError(name string) *fieldError        { return &fieldError{Name: name} }
-func (e *fieldError) Error() string                { return fmt.Sprintf(`field "%s": %s`, e.Name, e.Err.Error()) }
-func (e *fieldError) setErr(err error) *fieldError { e.Err = err; return e }
-
-type opError struct {
-	Op     string
-	X      any
-	Y      any
-	ArgNum int
-	ValIdx int
+// FieldError is an error of a field, which fails to be assigned or has an invalid type.
+// Type is the struct type of the field.
+// Tag is the fieldenum struct tag of the expression, and [Start, End) is the 1-based column range
+// of the invalid part of Tag. Tag is empty and Start and End are 0 if the error isn't located in a tag.
+type FieldError struct {
+	Err   error
+	Type  reflect.Type
+	Name  string
+	Tag   string
+	Start int
+	End   int
 }
 
-func newOpError(op string) *opError            { return &opError{Op: op} }
-func (e *opError) setUnsupported() *opError    { e.ArgNum, e.ValIdx = 0, 0; return e }
-func (e *opError) setUnaryType(x any) *opError { e.X, e.ArgNum, e.ValIdx = x, 1, 0; return e }
-func (e *opError) setBinaryType(x, y any) *opError {
-	e.X, e.Y, e.ArgNum, e.ValIdx = x, y, 2, 0
+func newFieldError(name string) *FieldError        { return &FieldError{Name: name} }
+func (e *FieldError) Unwrap() error                { return e.Err }
+func (e *FieldError) Error() string                { return fmt.Sprintf(`field "%s": %s`, e.Name, e.Err.Error()) }
+func (e *FieldError) setErr(err error) *FieldError { e.Err = err; return e }
+func (e *FieldError) setExpr(tag string, start, end int) *FieldError {
+	e.Tag, e.Start, e.End = tag, start, end
 	return e
 }
-func (e *opError) setUnaryVal(x any) *opError { e.X, e.ArgNum, e.ValIdx = x, 1, 1; return e }
-func (e *opError) setBinaryVal(x, y any, second bool) *opError {
-	e.X, e.Y, e.ArgNum = x, y, 2
-	if !second {
-		e.ValIdx = 1
-	} else {
-		e.ValIdx = 2
+
+// Diagnostic renders the error like compiler diagnostics,
+// with the struct type, the field name, the cause, the tag and an underline under the invalid part:
+//
+//	Limits.Max: call non-existed function "foo"
+//		1 + foo(2)
+//		    ^~~~~~
+//
+// Anonymous struct types are rendered as "struct{...}".
+func (e *FieldError) Diagnostic() string {
+	var builder strings.Builder
+	if e.Type != nil {
+		if name := e.Type.Name(); name != "" {
+			builder.WriteString(name + ".")
+		} else {
+			builder.WriteString("struct{...}.")
+		}
 	}
-	return e
-}
-func (e *opError) Error() string {
-	switch e.ValIdx {
-	case 0:
-		switch e.ArgNum {
-		case 1:
-			return fmt.Sprintf(`use operator "%s" on unsupported type for %s(%T)`, e.Op, e.Op, e.X)
-		case 2:
-			return fmt.Sprintf(`use operator "%s" on unsupported type for (%T)%s(%T)`, e.Op, e.X, e.Op, e.Y)
+	builder.WriteString(e.Name + ": ")
+
+	// the tag is rendered below, so the expression isn't repeated in the message
+	cause := e.Err
+	if exprErr, ok := e.Err.(*ExprError); ok && e.Tag != "" {
+		if cause = exprErr.Err; cause == nil {
+			cause = errors.New("invalid expression")
 		}
-	case 1:
-		switch e.ArgNum {
-		case 1:
-			return fmt.Sprintf(`use operator "%s" on unsupported value for %s(%v)`, e.Op, e.Op, e.X)
-		case 2:
-			return fmt.Sprintf(`use operator "%s" on unsupported first value for (%v)%s(%v)`, e.Op, e.X, e.Op, e.Y)
+	}
+	builder.WriteString(cause.Error())
+
+	if e.Tag == "" || e.Start < 1 {
+		return builder.String()
+	}
+	builder.WriteString("\n\t" + e.Tag + "\n\t")
+	for _, r := range e.Tag[:e.Start-1] {
+		if r == '\t' {
+			builder.WriteRune('\t')
+		} else {
+			builder.WriteRune(' ')
 		}
-	case 2:
-		return fmt.Sprintf(`use operator "%s" on unsupported second value for (%v)%s(%v)`, e.Op, e.X, e.Op, e.Y)
 	}
-	return `unsupported operator "` + e.Op + `"`
+	builder.WriteString("^")
+	if e.End > e.Start+1 {
+		builder.WriteString(strings.Repeat("~", e.End-e.Start-1))
+	}
+	return builder.String()
 }
 
-type funcError struct {
-	Func     string
-	Args     []any
-	NumRange *[2]int
-}
+// Errors is a list of errors of failing fields returned by [TryNew].
+// [errors.Is] and [errors.As] check every error by its Is and As methods,
+// and it supports unwrapping multiple errors like the result of errors.Join since Go 1.20.
+type Errors []error
 
-func newFuncError(fn string) *funcError                          { return &funcError{Func: fn} }
-func (e *funcError) setNotExisted() *funcError                   { e.Args = nil; return e }
-func (e *funcError) setUnsupportedArgType(args []any) *funcError { e.Args = args; return e }
-func (e *funcError) setNum(args []any, n int) *funcError         { return e.setNumRange(args, n, n+1) }
-func (e *funcError) setNumAtLeast(args []any, n int) *funcError  { return e.setNumRange(args, n, -1) }
-func (e *funcError) setNumAtMost(args []any, n int) *funcError   { return e.setNumRange(args, -1, n+1) }
-func (e *funcError) setNumRange(args []any, start, end int) *funcError {
-	e.Args, e.NumRange = args, &[2]int{start, end}
-	return e
-}
-func (e *funcError) Error() string {
-	switch {
-	case e.Args == nil:
-		return `call non-existed function "` + e.Func + `"`
-	case e.NumRange == nil:
-		var builder strings.Builder
-		builder.WriteString("call function " + e.Func + "(")
-		for i, arg := range e.Args {
-			if i > 0 {
-				builder.WriteString(" ")
-			}
-			builder.WriteString(fmt.Sprintf("%T", arg))
+func (e Errors) Unwrap() []error { return e }
+
+// Is reports whether any error in e matches target by [errors.Is].
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
 		}
-		builder.WriteString(") on unsupported type")
-		return builder.String()
-	case e.NumRange[0] >= 0 && len(e.Args) < e.NumRange[0]:
-		return "call function " + e.Func + " on too few arguments"
-	case e.NumRange[1] >= 0 && len(e.Args) >= e.NumRange[1]:
-		return "call function " + e.Func + " on too many arguments"
-	default:
-		return ""
 	}
+	return false
 }
 
-type exprError struct {
-	Fset  *token.FileSet
-	Err   error
-	Expr  string
-	Start token.Pos
-	End   token.Pos
-}
-
-func newExprError() *exprError                              { return &exprError{Start: -1} }
-func (e *exprError) setFset(fset *token.FileSet) *exprError { e.Fset = fset; return e }
-func (e *exprError) setErr(err error) *exprError            { e.Err = err; return e }
-func (e *exprError) setExpr(expr string) *exprError         { e.Expr = expr; return e }
-func (e *exprError) setPos(start, end token.Pos) *exprError { e.Start, e.End = start, end; return e }
-func (e *exprError) Unwrap() error                          { return e.Err }
-func (e *exprError) Error() string {
-	start, end := 0, len(e.Expr)
-	if e.Fset != nil {
-		start = e.Fset.Position(e.Start).Offset
-		if start < 0 {
-			start = 0
-		}
-		end = e.Fset.Position(e.End).Offset
-		if l := len(e.Expr); end > l || end <= 0 {
-			end = l
+// As finds the first error in e that matches target by [errors.As].
+func (e Errors) As(target any) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
 		}
 	}
-	if e.Err == nil {
-		return `invalid expression "` + e.Expr[start:end] + `"`
+	return false
+}
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
 	}
-	return e.Err.Error() + ` with expression "` + e.Expr[start:end] + `"`
+	return strings.Join(msgs, "\n")
 }
@@ -0,0 +1,93 @@
+package fieldenum
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Explanation explains how a field is assigned by [Explain].
+type Explanation struct {
+	// Name is the field name, qualified like "Auth.Expired" and "Flags[2]" for fields of groups and arrays.
+	// The base of a group field is explained by the name of the group field.
+	Name string
+	// Expr is the evaluated expression, which is rewritten to increment from a constant,
+	// e.g. "(iota-1)+(1 << 4)" for an expression "1 << 4" of the field of iota 1.
+	// Expr is empty for verbatim string fields.
+	Expr string
+	// Iota is the value of iota in Expr.
+	Iota int64
+	// Steps are the evaluated sub-expressions in evaluation order, the last one is Expr itself.
+	Steps []Step
+	// Value is the value assigned to the field, or nil if Err isn't nil.
+	Value any
+	// Err is the error of the field, which is also reported by [TryNew].
+	Err error
+}
+
+// Step is an evaluated sub-expression, Value has the promoted type of the evaluation,
+// e.g. int64 for "1", float64 for "1.0", or *big.Int and *big.Rat with [WithExactArithmetic].
+type Step struct {
+	Expr  string
+	Value any
+}
+
+// Explain assigns enum values to a new value of type T like [TryNew], and explains the assignment of every field
+// in assignment order, with the evaluated expression, iota, the values of sub-expressions and the assigned value.
+// Fields of type [enum.Enum] are explained without being registered.
+// The returned error is the error of [TryNew], failing fields are explained with their errors as well.
+func Explain[T any](options ...Option) ([]Explanation, error) {
+	conf, err := configure(options)
+	if err != nil {
+		return nil, err
+	}
+	conf.check, conf.explain = true, true
+	conf.Trace = func(expr string, value any) { conf.steps = append(conf.steps, Step{Expr: expr, Value: value}) }
+
+	v, info, err := valueAndType(reflect.ValueOf(new(T)))
+	if err != nil {
+		return nil, err
+	}
+	err = assignEnums(conf, v, info)
+	return conf.explanations, err
+}
+
+// String renders the explanation like:
+//
+//	B = (iota-0)+(10) with iota = 1
+//		iota = 1 (int64)
+//		...
+//		(iota-0)+(10) = 11 (int64)
+//		=> 11 (int8)
+func (e Explanation) String() string {
+	var builder strings.Builder
+	builder.WriteString(e.Name)
+	if e.Expr != "" {
+		fmt.Fprintf(&builder, " = %s with iota = %d", e.Expr, e.Iota)
+	}
+	for _, step := range e.Steps {
+		fmt.Fprintf(&builder, "\n\t%s = %v (%T)", step.Expr, step.Value, step.Value)
+	}
+	if e.Err != nil {
+		builder.WriteString("\n\terror: " + e.Err.Error())
+	} else {
+		fmt.Fprintf(&builder, "\n\t=> %v (%T)", e.Value, e.Value)
+	}
+	return builder.String()
+}
+
+// explainField records the explanation of the field named name assigned by assignField,
+// expr is the evaluated expression, and v is the assigned value, or the number of enum.Enum fields.
+func (conf *config) explainField(name, expr string, v reflect.Value, err *FieldError) {
+	e := Explanation{Name: conf.group + name, Expr: expr, Steps: conf.steps}
+	if expr != "" {
+		e.Iota, _ = conf.Values["iota"].(int64)
+	}
+	if err != nil {
+		e.Err = err
+	} else {
+		e.Value = v.Interface()
+	}
+	conf.explanations = append(conf.explanations, e)
+	conf.steps = nil
+}
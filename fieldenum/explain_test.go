@@ -0,0 +1,79 @@
+package fieldenum
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/QAQandOwO/godget/enum"
+)
+
+type explainLevel struct{}
+
+type explainEnum struct {
+	A uint8 `fieldenum:"1 << iota"`
+	B uint8
+	C string
+	D struct {
+		X int8 `fieldenum:"iota * 2.0"`
+	} `fieldenum:"base=10"`
+	E [2]int                  `fieldenum:"iota + 1"`
+	F int8                    `fieldenum:"A * 200"`
+	G enum.Enum[explainLevel] `fieldenum:"-1"`
+}
+
+func TestExplain(t *testing.T) {
+	explanations, err := Explain[explainEnum]()
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Name != "F" {
+		t.Errorf("ERROR: got error %v, want error of F", err)
+	}
+
+	wants := []struct {
+		name  string
+		expr  string
+		iota  int64
+		steps int
+		value any
+		err   bool
+	}{
+		0: {name: "A", expr: "1 << iota", iota: 0, steps: 3, value: uint8(1)},
+		1: {name: "B", expr: "1 << iota", iota: 1, steps: 3, value: uint8(2)},
+		2: {name: "C", value: "C"},
+		3: {name: "D", expr: "10", iota: 3, steps: 1, value: int64(10)},
+		4: {name: "D.X", expr: "iota * 2.0", iota: 10, steps: 3, value: int8(20)},
+		5: {name: "E[0]", expr: "iota + 1", iota: 0, steps: 3, value: 1},
+		6: {name: "E[1]", expr: "iota + 1", iota: 1, steps: 3, value: 2},
+		7: {name: "F", expr: "(iota-5)+(A * 200)", iota: 5, steps: 7, err: true},
+		8: {name: "G", expr: "(iota-6)+(-1)", iota: 6, steps: 6, value: -1},
+	}
+	if len(explanations) != len(wants) {
+		t.Fatalf("ERROR: got %d explanations %v, want %d", len(explanations), explanations, len(wants))
+	}
+	for i, want := range wants {
+		got := explanations[i]
+		if got.Name != want.name || got.Expr != want.expr || got.Iota != want.iota || len(got.Steps) != want.steps ||
+			got.Value != want.value || (got.Err != nil) != want.err {
+			t.Errorf("[%d]ERROR: got %s, want %+v", i, got, want)
+		}
+	}
+
+	want := "F = (iota-5)+(A * 200) with iota = 5\n" +
+		"\tiota = 5 (int64)\n" +
+		"\t5 = 5 (int64)\n" +
+		"\tiota-5 = 0 (int64)\n" +
+		"\tA = 1 (int64)\n" +
+		"\t200 = 200 (int64)\n" +
+		"\tA * 200 = 200 (int64)\n" +
+		"\t(iota-5)+(A * 200) = 200 (int64)\n" +
+		"\terror: field \"F\": value \"200\" overflows type int8"
+	if got := explanations[7].String(); got != want {
+		t.Errorf("ERROR: got\n%s\nwant\n%s", got, want)
+	}
+
+	if _, ok := enum.GetEnumByName[explainLevel]("G"); ok {
+		t.Errorf("ERROR: got registered enum G, want not registered")
+	}
+	if _, err = Explain[int](); err == nil {
+		t.Errorf("ERROR: got no error, want error of invalid type")
+	}
+}
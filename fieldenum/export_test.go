@@ -0,0 +1,9 @@
+package fieldenum
+
+// ResetShared clears the values cached by Shared, so that they are assigned again on the next call.
+func ResetShared() {
+	sharedValues.Range(func(key, _ any) bool {
+		sharedValues.Delete(key)
+		return true
+	})
+}
@@ -0,0 +1,171 @@
+// Package expr exports the arithmetic expression evaluator used by package fieldenum.
+//
+// Expressions follow Go syntax and support the same literals, operators, built-in constants
+// and built-in functions as fieldenum struct tags, including the int64, float64 and complex128
+// type promotion rules. See the package [fieldenum] documentation for details.
+// The identifier iota of struct tags is unsupported, and can't be registered by [WithValues].
+//
+// Example:
+//
+//	prog, err := expr.Compile("base * pow(2, n)")
+//	if err != nil {
+//		// handle error
+//	}
+//	env, err := expr.NewEnv(expr.WithValues(map[string]any{"base": 3, "n": 4}))
+//	if err != nil {
+//		// handle error
+//	}
+//	v, err := prog.Eval(env) // int64(48)
+//
+// [fieldenum]: https://pkg.go.dev/github.com/QAQandOwO/godget/fieldenum
+package expr
+
+import (
+	"context"
+
+	"github.com/QAQandOwO/godget/fieldenum/internal/engine"
+)
+
+// Func is a function which can be called in expressions.
+// It has the same contract as fieldenum.ExprFunc.
+type Func = func(values []any) (any, error)
+
+// Option is a function to configure Env.
+type Option = func(env *Env) error
+
+// WithFuncs registers functions to configure Env.
+// The function name must be unique, otherwise NewEnv returns an error.
+// Built-in functions added after WithFuncs, like floor and round, are shadowed by registered functions instead.
+// Built-in functions convert results to int64, float64, or complex128.
+// It's recommended that custom functions also process and return these three numeric types.
+func WithFuncs(funcs map[string]Func) Option {
+	return func(env *Env) error { return env.conf.AddFuncs(funcs) }
+}
+
+// WithValues registers values to configure Env.
+// The value name must be unique, otherwise NewEnv returns an error.
+// During evaluation, numeric values are converted to int64, float64, or complex128.
+func WithValues(values map[string]any) Option {
+	return func(env *Env) error { return env.conf.AddValues(values) }
+}
+
+// WithUnits registers unit identifiers, so that sizes and durations can be written like "4*MiB" and "30*s":
+//   - Sizes in bytes: KiB, MiB, GiB, TiB, PiB
+//   - Durations in nanoseconds like [time.Duration]: ns, us, µs, ms, s, min, h
+//
+// Units conflict with values of [WithValues] of the same names, otherwise NewEnv returns an error.
+func WithUnits() Option {
+	return func(env *Env) error { return env.conf.AddValues(engine.UnitValues) }
+}
+
+// WithMaxDepth limits the nesting depth of the syntax tree of programs to n, for example,
+// the depth of "1 + 2*3" is 3. Programs exceeding the limit are not evaluated.
+func WithMaxDepth(n int) Option {
+	return func(env *Env) error {
+		env.conf.Limits.MaxDepth = n
+		return nil
+	}
+}
+
+// WithMaxNodes limits the number of nodes of the syntax tree of programs to n, for example,
+// "max(1, 2)" has 3 nodes. Programs exceeding the limit are not evaluated.
+func WithMaxNodes(n int) Option {
+	return func(env *Env) error {
+		env.conf.Limits.MaxNodes = n
+		return nil
+	}
+}
+
+// WithAllowedFuncs allows programs to call only the named built-in and registered functions.
+// The conditional function if is always allowed.
+// Multiple WithAllowedFuncs options allow the union of names, and no names disallow all functions.
+func WithAllowedFuncs(names ...string) Option {
+	return func(env *Env) error {
+		if env.conf.Limits.Funcs == nil {
+			env.conf.Limits.Funcs = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			env.conf.Limits.Funcs[name] = true
+		}
+		return nil
+	}
+}
+
+// LimitError is an error of an evaluation exceeding the limits set by [WithMaxDepth],
+// [WithMaxNodes] and [WithAllowedFuncs], or stopped by the context of [Program.EvalContext].
+type LimitError = engine.LimitError
+
+// Limits reported by [LimitError].
+const (
+	LimitDepth   = engine.LimitDepth   // nesting depth set by WithMaxDepth
+	LimitNodes   = engine.LimitNodes   // number of nodes set by WithMaxNodes
+	LimitFunc    = engine.LimitFunc    // function not allowed by WithAllowedFuncs
+	LimitContext = engine.LimitContext // context of EvalContext is done
+)
+
+// Env holds the values and functions available to a Program.
+// Env must not be modified after creation, and can be shared by concurrent evaluations.
+type Env struct {
+	conf *engine.Config
+}
+
+// NewEnv creates an Env with built-in constants and functions, configured by options.
+func NewEnv(options ...Option) (*Env, error) {
+	env := &Env{conf: engine.NewConfig()}
+	for _, option := range options {
+		if err := option(env); err != nil {
+			return nil, err
+		}
+	}
+	return env, nil
+}
+
+// Program is a compiled expression, which can be evaluated many times.
+type Program struct {
+	prog *engine.Program
+}
+
+// Compile parses an expression following Go syntax.
+// It returns an error if the expression has invalid syntax.
+func Compile(src string) (*Program, error) {
+	prog, err := engine.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{prog: prog}, nil
+}
+
+// MustCompile is like Compile but panics if the expression has invalid syntax.
+func MustCompile(src string) *Program {
+	prog, err := Compile(src)
+	if err != nil {
+		panic(err)
+	}
+	return prog
+}
+
+// String returns the source expression of the program.
+func (p *Program) String() string { return p.prog.Expr }
+
+// Eval evaluates the program with values and functions of env.
+// If env is nil, only built-in constants and functions are available.
+// The result is int64, float64, complex128, or a value returned by a custom function.
+func (p *Program) Eval(env *Env) (any, error) {
+	if env == nil {
+		env = &Env{conf: engine.NewConfig()}
+	}
+	return p.prog.Eval(env.conf)
+}
+
+// EvalContext is like Eval but stops the evaluation once ctx is done.
+// The context is checked before every binary operation and function call,
+// but running functions are not interrupted.
+// It returns an error wrapping a [*LimitError] and ctx.Err() if the evaluation is stopped.
+func (p *Program) EvalContext(ctx context.Context, env *Env) (any, error) {
+	if env == nil {
+		env = &Env{conf: engine.NewConfig()}
+	}
+	conf := *env.conf
+	conf.Limits.Context = ctx
+	return p.prog.Eval(&conf)
+}
@@ -0,0 +1,117 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestProgram_Eval(t *testing.T) {
+	double := func(values []any) (any, error) {
+		if len(values) != 1 {
+			return nil, errors.New("double requires exactly 1 argument")
+		}
+		x, ok := values[0].(int64)
+		if !ok {
+			return nil, errors.New("unsupported type")
+		}
+		return 2 * x, nil
+	}
+
+	tests := []struct {
+		src     string
+		options []Option
+		want    any
+		retErr  bool
+	}{
+		0:  {src: "1+2*3", want: int64(7)},
+		1:  {src: "pow(2, 10)", want: int64(1024)},
+		2:  {src: "sqrt(4.0)", want: 2.0},
+		3:  {src: "9223372036854775807+1", want: 9223372036854775808.0},
+		4:  {src: "a*b", options: []Option{WithValues(map[string]any{"a": 3, "b": int64(4)})}, want: int64(12)},
+		5:  {src: "double(21)", options: []Option{WithFuncs(map[string]Func{"double": double})}, want: int64(42)},
+		6:  {src: "pi", want: math.Pi},
+		7:  {src: "a", retErr: true},
+		8:  {src: "double(1)", retErr: true},
+		9:  {src: "1.0&1", retErr: true},
+		10: {src: "abs(-2)*3", options: []Option{WithMaxDepth(4), WithMaxNodes(5), WithAllowedFuncs("abs")}, want: int64(6)},
+		11: {src: "abs(-2)*3", options: []Option{WithMaxDepth(3)}, retErr: true},
+		12: {src: "abs(-2)*3", options: []Option{WithMaxNodes(4)}, retErr: true},
+		13: {src: "abs(-2)*3", options: []Option{WithAllowedFuncs()}, retErr: true},
+		14: {src: "4*MiB + 30*s/ms", options: []Option{WithUnits()}, want: int64(4<<20 + 30000)},
+		15: {src: "MiB", retErr: true},
+		16: {src: "iota", retErr: true},
+		17: {src: "1 + iota", retErr: true},
+	}
+
+	for i, test := range tests {
+		prog, err := Compile(test.src)
+		if err != nil {
+			t.Errorf("[%d]ERROR: got %v, want no error", i, err)
+			continue
+		}
+		env, err := NewEnv(test.options...)
+		if err != nil {
+			t.Errorf("[%d]ERROR: got %v, want no error", i, err)
+			continue
+		}
+
+		got, err := prog.Eval(env)
+		switch {
+		case test.retErr && err == nil:
+			t.Errorf("[%d]ERROR: got %v, want error", i, got)
+		case !test.retErr && err != nil:
+			t.Errorf("[%d]ERROR: got %v, want no error", i, err)
+		case got != test.want:
+			t.Errorf("[%d]ERROR: got %v(%T), want %v(%T)", i, got, got, test.want, test.want)
+		}
+	}
+}
+
+func TestProgram_EvalContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	env, err := NewEnv(WithAllowedFuncs("max"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog := MustCompile("max(1, 2) + 3")
+	if got, err := prog.EvalContext(ctx, env); err != nil || got != int64(5) {
+		t.Errorf("ERROR: got %v %v, want 5", got, err)
+	}
+
+	cancel()
+	_, err = prog.EvalContext(ctx, env)
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != LimitContext || !errors.Is(err, context.Canceled) {
+		t.Errorf("ERROR: got %v, want *LimitError wrapping context.Canceled", err)
+	}
+	if _, err = MustCompile("abs(1)").EvalContext(context.Background(), env); !errors.As(err, &limitErr) || limitErr.Func != "abs" {
+		t.Errorf("ERROR: got %v, want *LimitError of abs", err)
+	}
+	// the context of EvalContext doesn't leak into env
+	if got, err := prog.Eval(env); err != nil || got != int64(5) {
+		t.Errorf("ERROR: got %v %v, want 5", got, err)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	if _, err := Compile("1+"); err == nil {
+		t.Errorf("ERROR: got no error, want error")
+	}
+	if got := MustCompile("1 + iota").String(); got != "1 + iota" {
+		t.Errorf("ERROR: got %q, want %q", got, "1 + iota")
+	}
+	if got, err := MustCompile("2*e").Eval(nil); err != nil || got != 2*math.E {
+		t.Errorf("ERROR: got %v %v, want %v", got, err, 2*math.E)
+	}
+}
+
+func TestNewEnv(t *testing.T) {
+	if _, err := NewEnv(WithValues(map[string]any{"pi": 3})); err == nil {
+		t.Errorf("ERROR: got no error, want error for existed value")
+	}
+	if _, err := NewEnv(WithFuncs(map[string]Func{"sqrt": nil})); err == nil {
+		t.Errorf("ERROR: got no error, want error for existed function")
+	}
+}
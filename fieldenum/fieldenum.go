@@ -1,34 +1,42 @@
 package fieldenum
 
 import (
+	"context"
+	"encoding"
 	"errors"
 	"fmt"
-	"go/ast"
-	"go/token"
+	"math"
+	"math/big"
 	"reflect"
 	"strconv"
 	"strings"
+	"unicode"
+
+	"github.com/QAQandOwO/godget/enum"
+	"github.com/QAQandOwO/godget/fieldenum/internal/engine"
 )
 
+// ExprFunc is a function which can be called in expressions.
+type ExprFunc = func(values []any) (any, error)
+
 // Option is a function to configure fieldenum.
 type Option = func(config *config) error
 
 // WithFuncs registers functions to configure fieldenum.
 // The function name must be unique, otherwise it will panic.
+// Built-in functions added after WithFuncs, like floor and round, are shadowed by registered functions instead.
 // Built-in functions convert results to int64, float64, or complex128.
 // It's recommended that custom functions also process and return these three numeric types.
+// Typed Go functions can be adapted by [Func1], [Func2] and [FuncVariadic].
 func WithFuncs(funcs map[string]ExprFunc) Option {
-	return func(conf *config) error {
-		for name, fn := range funcs {
-			if _, ok := conf.function(name); ok {
-				return errors.New(`existed function with name "` + name + `"`)
-			}
-			conf.funcs[name] = fn
-		}
-		return nil
-	}
+	return func(conf *config) error { return conf.AddFuncs(funcs) }
 }
 
+// ReservedFunc reports whether name is a built-in function which [WithFuncs] can't register,
+// i.e. calls of it are always evaluated by the built-in function.
+// Built-in functions added after WithFuncs, like floor, aren't reserved and are shadowed by registered functions.
+func ReservedFunc(name string) bool { return engine.ReservedFunc(name) }
+
 // WithValues registers values to configure fieldenum.
 // The value name must be unique, otherwise it will panic.
 // Values should be numeric types, otherwise it will panic.
@@ -37,13 +45,148 @@ func WithFuncs(funcs map[string]ExprFunc) Option {
 // it will overflow when converted to int64.
 // It's recommended to use only int64, float64, and complex128 types for values.
 func WithValues(values map[string]any) Option {
+	return func(conf *config) error { return conf.AddValues(values) }
+}
+
+// WithStringExpr enables expressions in fieldenum struct tags of string fields.
+// By default, the tag of a string field is used verbatim as the field value.
+// With this option, the tag is evaluated as an expression, which can use string literals,
+// "+" concatenation, string functions and the identifier name bound to the field name.
+// String fields without fieldenum tag evaluate the expression of the previous string field,
+// or are set to the field name if there is no previous one.
+func WithStringExpr() Option {
 	return func(conf *config) error {
-		for name, value := range values {
-			if _, ok := conf.value(name); ok {
-				return errors.New(`existed value with name "` + name + `"`)
-			}
-			conf.values[name] = value
+		conf.stringExpr = true
+		return nil
+	}
+}
+
+// WithExactArithmetic evaluates numeric expressions exactly with [math/big].
+// Integers are evaluated as [big.Int] without overflow, and floats as [big.Rat] without rounding,
+// then the result must fit the field type exactly, otherwise an error is reported.
+// Integer fields reject results out of range or with a fractional part,
+// and float fields reject results out of range and integers they can't represent exactly.
+// Fractions are rounded to float64 like Go constants, and float32 and complex64 fields reject
+// results which they round further, e.g. 0.1.
+// Functions without exact implementation and complex numbers are evaluated as usual.
+func WithExactArithmetic() Option {
+	return func(conf *config) error {
+		conf.Exact = true
+		return nil
+	}
+}
+
+// WithLenientAssignment assigns results to fields without overflow and precision checks.
+// By default, an error is reported if the result is out of range of the field type,
+// if a float result with a fractional part, NaN or infinity is assigned to an integer field,
+// if an integer result can't be represented exactly by a float field,
+// or if a float result is rounded by a float32 or complex64 field, e.g. 0.1 and pow(1i, 2).
+// With this option, results are converted like Go conversions instead,
+// e.g. 300 is wrapped to 44 for int8 fields, and 3.5 is truncated to 3 for int fields.
+func WithLenientAssignment() Option {
+	return func(conf *config) error {
+		conf.lenient = true
+		return nil
+	}
+}
+
+// WithUnits registers unit identifiers, so that sizes and durations can be written like "4*MiB" and "30*s":
+//   - Sizes in bytes: KiB, MiB, GiB, TiB, PiB
+//   - Durations in nanoseconds like [time.Duration]: ns, us, µs, ms, s, min, h
+//
+// Units are values, so they conflict with values of [WithValues] of the same names,
+// and shadow fields of the same names. The identifier min doesn't affect the function min().
+func WithUnits() Option {
+	return func(conf *config) error { return conf.AddValues(engine.UnitValues) }
+}
+
+// WithIntFormat formats integer results of expressions of string fields with format, such as "0x%04X" and "%b",
+// instead of decimal. It requires [WithStringExpr], and the format must format an integer with one verb,
+// otherwise it will panic.
+func WithIntFormat(format string) Option {
+	return func(conf *config) error {
+		if err := checkFormat(format, int64(0)); err != nil {
+			return err
 		}
+		conf.intFormat = format
+		return nil
+	}
+}
+
+// WithFloatFormat formats float and complex results of expressions of string fields with format, such as "%.3g",
+// instead of strconv.FormatFloat(x, 'f', -1, 64), whose output of huge or tiny values is long.
+// It requires [WithStringExpr], and the format must format a float with one verb, otherwise it will panic.
+// With [WithExactArithmetic], fractions are formatted as float64.
+func WithFloatFormat(format string) Option {
+	return func(conf *config) error {
+		if err := checkFormat(format, 0.0); err != nil {
+			return err
+		}
+		conf.floatFormat = format
+		return nil
+	}
+}
+
+// WithFormatter formats numeric results of expressions of string fields by fn, overriding [WithIntFormat]
+// and [WithFloatFormat]. It requires [WithStringExpr].
+// The value is int64, float64 or complex128, or *big.Int for integers out of range of int64
+// with [WithExactArithmetic], in which fractions are passed as float64.
+func WithFormatter(fn func(value any) string) Option {
+	return func(conf *config) error {
+		conf.formatter = fn
+		return nil
+	}
+}
+
+// checkFormat returns an error if format doesn't format value with one verb.
+func checkFormat(format string, value any) error {
+	if out := fmt.Sprintf(format, value); strings.Contains(out, "%!") {
+		return fmt.Errorf(`invalid format "%s" for type %T`, format, value)
+	}
+	return nil
+}
+
+// WithMaxDepth limits the nesting depth of the syntax tree of expressions to n, for example,
+// the depth of "1 + 2*3" is 3.
+// Expressions exceeding the limit are reported as [*LimitError] without evaluation.
+func WithMaxDepth(n int) Option {
+	return func(conf *config) error {
+		conf.Limits.MaxDepth = n
+		return nil
+	}
+}
+
+// WithMaxNodes limits the number of nodes of the syntax tree of expressions to n, for example,
+// "max(1, 2)" has 3 nodes.
+// Expressions exceeding the limit are reported as [*LimitError] without evaluation.
+func WithMaxNodes(n int) Option {
+	return func(conf *config) error {
+		conf.Limits.MaxNodes = n
+		return nil
+	}
+}
+
+// WithAllowedFuncs allows expressions to call only the named built-in and registered functions,
+// calls of other functions are reported as [*LimitError]. The conditional function if is always allowed.
+// Multiple WithAllowedFuncs options allow the union of names, and no names disallow all functions.
+func WithAllowedFuncs(names ...string) Option {
+	return func(conf *config) error {
+		if conf.Limits.Funcs == nil {
+			conf.Limits.Funcs = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			conf.Limits.Funcs[name] = true
+		}
+		return nil
+	}
+}
+
+// WithContext stops evaluations once ctx is done, e.g. on a deadline of expressions from untrusted sources.
+// The context is checked before every binary operation and function call,
+// and fields evaluated after ctx is done are reported as [*LimitError] wrapping ctx.Err().
+func WithContext(ctx context.Context) Option {
+	return func(conf *config) error {
+		conf.Limits.Context = ctx
 		return nil
 	}
 }
@@ -52,7 +195,6 @@ func WithValues(values map[string]any) Option {
 //
 // Type T must meet the following conditions, otherwise it will panic:
 //   - Must be a struct or struct pointer type
-//   - All struct fields must be of the same type
 //   - Fields must be exported
 //   - Field underlying types must be one of:
 //     int, int8, int16, int32, int64,
@@ -60,6 +202,9 @@ func WithValues(values map[string]any) Option {
 //     float32, float64,
 //     complex64, complex128,
 //     string
+//   - Or field types must be [enum.Enum]
+//   - Or field types must be structs meeting the same conditions, assigned as groups
+//   - Or field types must be arrays of numeric types
 //
 // The types listed above except string are referred to as numeric types.
 //
@@ -67,15 +212,33 @@ func WithValues(values map[string]any) Option {
 //   - If no fieldenum struct tag is present, field value is set to field name
 //   - Otherwise use the value specified in the field tag
 //
+// Fields of different types can be mixed in one struct, each field is assigned by the rules of its own type.
+// String fields are skipped by numeric expressions, but still count for iota.
+//
 // When field type is numeric, assignment rules are:
 //   - Arithmetic expressions can be set via fieldenum struct tag
 //   - Arithmetic expressions follow Go syntax, panic on invalid syntax
 //   - If a field has fieldenum tag, subsequent fields reset to current expression
 //   - iota placeholder can be used in expressions, representing current field index
+//   - Names of previously assigned fields can be used in expressions, representing their values
 //   - Default assignment expression is "iota"
 //   - If expression contains "iota", no special processing is done
 //   - If expression doesn't contain "iota", subsequent fields increment from current result
 //   - Empty fieldenum tag is treated as "0"
+//   - Tags starting with "-", expr=, skip, reset, step= or base= are tag options, see the package documentation
+//   - Results must fit the field type without overflow or precision loss, see [WithLenientAssignment]
+//
+// When field type is [enum.Enum], the field is registered as an enum value:
+//   - Field name is used as the enum name, so it must be unique for the enum type
+//   - Fields are registered only if all fields are assigned without errors
+//   - Enum number is assigned with the same rules as int fields
+//
+// When field type is a struct, the field is a group assigned recursively:
+//   - The fieldenum tag "base=expr" makes iota of the group fields count from base
+//   - The base of a nested group is relative to the base of the enclosing group
+//   - Fields of enclosing structs can be referenced in expressions of the group fields
+//
+// When field type is an array, elements are assigned like numeric fields with iota of the element index.
 //
 // Built-in constants and functions are available for numeric field types.
 // Custom values can be registered using WithValues - values should be numeric types.
@@ -84,156 +247,659 @@ func WithValues(values map[string]any) Option {
 // For numeric field types, built-in constants and functions are available.
 // See the package [fieldenum] documentation for details on available constants and functions.
 // For more information about fieldenum struct tags, refer to the package [fieldenum] documentation.
+//
+// Struct tags of type T are parsed and compiled only once, subsequent calls evaluate the cached expressions.
 func New[T any](options ...Option) T {
-	enums, err := assign[T](options)
+	enums, err := TryNew[T](options...)
 	if err != nil {
 		panic(newFieldEnumError(err))
 	}
-	return *enums
+	return enums
+}
+
+// TryNew is like New but returns an error instead of panicking.
+//
+// If any field fails to be assigned, the returned error is [Errors],
+// which holds a [*FieldError] for every failing field in field order.
+// Fields referencing a failing field are not reported again.
+// Errors of invalid options and invalid type T are returned as is.
+func TryNew[T any](options ...Option) (T, error) {
+	enums, err := assign[T](options)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return *enums, nil
 }
 
 type config struct {
-	funcs  map[string]ExprFunc
-	values map[string]any
+	*engine.Config
+	fields     map[string]*fieldRef   // fields of the struct being assigned
+	outer      []map[string]*fieldRef // fields of the enclosing structs of groups, innermost last
+	current    *fieldRef              // field being assigned, nil for groups and arrays
+	name       string                 // name of the field being assigned
+	stringExpr bool
+	lenient    bool
+	check      bool // check expressions without registering enum.Enum fields
+	enums      []pendingEnum
+
+	// explanations of Explain
+	explain      bool
+	group        string // qualifier of fields of the group being assigned, e.g. "Auth."
+	steps        []Step // steps of the expression being evaluated
+	explanations []Explanation
+
+	// formats of numeric results of string fields
+	intFormat   string
+	floatFormat string
+	formatter   func(value any) string
 }
 
-func newConfig() *config { return &config{make(map[string]ExprFunc), make(map[string]any)} }
-func (conf *config) function(name string) (ExprFunc, bool) {
-	fn, ok := builtinFuncs[name]
-	if ok {
-		return fn, true
+// pendingEnum is an enum.Enum field registered after all fields are assigned without errors.
+type pendingEnum struct {
+	v      reflect.Value
+	name   string // qualified field name
+	enum   string // enum name, the field name
+	number int
+}
+
+// fieldRef holds the value of a field which can be referenced in expressions.
+type fieldRef struct {
+	value    any
+	assigned bool
+	failed   bool
+}
+
+func newConfig() *config {
+	conf := &config{Config: engine.NewConfig()}
+	conf.Lookup = conf.field
+	return conf
+}
+
+// field resolves identifiers referencing fields.
+// Fields of the struct being assigned shadow fields of enclosing structs with the same name.
+func (conf *config) field(name string) (any, error) {
+	if name == "name" {
+		return conf.name, nil
+	}
+
+	ref, ok := conf.fields[name]
+	for i := len(conf.outer) - 1; !ok && i >= 0; i-- {
+		ref, ok = conf.outer[i][name]
+	}
+	switch {
+	case !ok:
+		return nil, nil
+	case ref.assigned:
+		return ref.value, nil
+	case ref.failed:
+		return nil, fmt.Errorf(`%w "%s"`, errFailedField, name)
+	case ref == conf.current:
+		return nil, fmt.Errorf(`reference field "%s" itself`, name)
+	default:
+		return nil, fmt.Errorf(`reference field "%s" before assignment`, name)
 	}
-	fn, ok = conf.funcs[name]
-	return fn, ok
 }
-func (conf *config) value(name string) (any, bool) {
-	if name == "iota" {
-		return conf.values["iota"], true
+
+// Check reports errors of fieldenum struct tags of type t like [TryNew] without returning the result.
+// Type t must be a struct or struct pointer type accepted by [New].
+// Unlike TryNew, [enum.Enum] fields are checked without being registered,
+// so Check can be called for types which are assigned by New elsewhere.
+func Check(t reflect.Type, options ...Option) error {
+	conf, err := configure(options)
+	if err != nil {
+		return err
 	}
-	value, ok := builtinValues[name]
-	if ok {
-		return value, true
+	conf.check = true
+
+	info := loadTypeInfo(t)
+	if info.err != nil {
+		return info.err
 	}
-	value, ok = conf.values[name]
-	return value, ok
+	return assignEnums(conf, reflect.New(info.typ).Elem(), info)
 }
 
-func assign[T any](options []Option) (enums *T, err error) {
+func configure(options []Option) (*config, error) {
 	conf := newConfig()
 	for _, option := range options {
-		if err = option(conf); err != nil {
+		if err := option(conf); err != nil {
 			return nil, err
 		}
 	}
+	return conf, nil
+}
 
-	enums = new(T)
-	v, t, kind, err := valueAndType(enums)
-	if err != nil {
+// NewValue is like [TryNew] but for struct type t known only at runtime,
+// e.g. a type built by [reflect.StructOf] in code generators. Type t must meet the requirements of T of [New].
+// It returns a new value of type t, or the zero Value and an error.
+func NewValue(t reflect.Type, options ...Option) (reflect.Value, error) {
+	ptr := reflect.New(t)
+	if err := assignValue(ptr, options); err != nil {
+		return reflect.Value{}, err
+	}
+	return ptr.Elem(), nil
+}
+
+func assign[T any](options []Option) (*T, error) {
+	enums := new(T)
+	if err := assignValue(reflect.ValueOf(enums), options); err != nil {
 		return nil, err
 	}
+	return enums, nil
+}
 
-	if kind == stringKind {
-		assignStringEnums(v, t)
-	} else {
-		err = assignNumberEnums(conf, v, t, kind)
+func assignValue(ptr reflect.Value, options []Option) error {
+	conf, err := configure(options)
+	if err != nil {
+		return err
 	}
-	return
+
+	v, info, err := valueAndType(ptr)
+	if err != nil {
+		return err
+	}
+	return assignEnums(conf, v, info)
 }
 
-func valueAndType[T any](ptr *T) (reflect.Value, reflect.Type, uint8, error) {
-	v := reflect.ValueOf(ptr).Elem()
-	t := v.Type()
-	if t.Kind() == reflect.Pointer {
-		t = t.Elem()
-		v.Set(reflect.New(t))
-		v = reflect.ValueOf(ptr).Elem().Elem()
+func valueAndType(ptr reflect.Value) (reflect.Value, *typeInfo, error) {
+	info := loadTypeInfo(ptr.Type().Elem())
+	if info.err != nil {
+		return reflect.Value{}, nil, info.err
 	}
 
-	if t.Kind() != reflect.Struct {
-		return reflect.Value{}, nil, invalidKind, fmt.Errorf(`invalid type "%T"`, *ptr)
+	v := ptr.Elem()
+	if info.isPtr {
+		v.Set(reflect.New(info.typ))
+		v = v.Elem()
 	}
+	return v, info, nil
+}
 
-	var tf reflect.Type
-	var kind uint8
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		tField := field.Type
+// assignEnums assigns all fields and collects errors of failing fields.
+// [enum.Enum] fields are registered only if all fields are assigned without errors.
+func assignEnums(conf *config, v reflect.Value, info *typeInfo) error {
+	fieldErrs := assignGroup(conf, v, info, 0)
+	if len(fieldErrs) == 0 {
+		fieldErrs = conf.registerEnums()
+	}
+	if len(fieldErrs) == 0 {
+		return nil
+	}
+	errs := make(Errors, len(fieldErrs))
+	for i, err := range fieldErrs {
+		err.Type = info.typ
+		errs[i] = err
+	}
+	return errs
+}
+
+// assignGroup assigns the fields of struct v, iota of which counts from base.
+// The same error of an expression shared by fields without fieldenum tag is reported only once.
+// Errors of fields of nested groups are named by the qualified field name, e.g. "Auth.Expired".
+func assignGroup(conf *config, v reflect.Value, info *typeInfo, base int64) []*FieldError {
+	if conf.fields != nil {
+		conf.outer = append(conf.outer, conf.fields)
+		defer func() {
+			conf.fields, conf.outer = conf.outer[len(conf.outer)-1], conf.outer[:len(conf.outer)-1]
+		}()
+	}
+	conf.fields = make(map[string]*fieldRef, len(info.names))
+	for i, name := range info.names {
+		if kind := info.kinds[i]; kind != groupKind && kind != arrayKind && !info.progs[i].ignore {
+			conf.fields[name] = &fieldRef{}
+		}
+	}
 
-		err := newFieldError(field.Name)
+	type progErr struct {
+		prog *engine.Program
+		msg  string
+	}
+	var errs []*FieldError
+	reported := make(map[progErr]bool)
+	report := func(err *FieldError, prog fieldProgram) {
+		// errors of tag options belong to the field rather than the shared program
+		key := progErr{prog: prog.Program, msg: err.Err.Error()}
+		if prog.tagErr != nil || !reported[key] && !errors.Is(err, errFailedField) {
+			errs = append(errs, err)
+		}
+		reported[key] = true
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field, fv, prog := info.typ.Field(i), v.Field(i), info.progs[i]
+		conf.current = conf.fields[field.Name]
 		switch {
-		case !v.Field(i).CanSet() || !field.IsExported():
-			return reflect.Value{}, nil, invalidKind, err.setErr(errors.New(`is not settable`))
-		case tf == nil:
-			if kind = fieldKinds[tField.Kind()]; kind == invalidKind {
-				return reflect.Value{}, nil, invalidKind, err.setErr(fmt.Errorf(`invalid type "%s"`, tField.String()))
+		case prog.ignore:
+			continue
+		case info.kinds[i] == groupKind:
+			conf.setIota(prog, base+int64(prog.iota), prog.iota)
+			groupBase, err := assignBase(conf, field, prog, base)
+			if err != nil {
+				report(err, prog)
+				continue
+			}
+			group := conf.group
+			conf.group += field.Name + "."
+			for _, err := range assignGroup(conf, fv, info.groups[i], groupBase) {
+				err.Name = field.Name + "." + err.Name
+				errs = append(errs, err)
+			}
+			conf.group = group
+		case info.kinds[i] == arrayKind:
+			kind := fieldKind(fv.Type().Elem())
+			for j := 0; j < fv.Len(); j++ {
+				conf.setIota(prog, base+int64(j), prog.iota+j)
+				name := field.Name + "[" + strconv.Itoa(j) + "]"
+				if _, err := assignField(conf, fv.Index(j), field, name, kind, prog); err != nil {
+					report(err, prog)
+				}
+			}
+		default:
+			conf.setIota(prog, base+int64(prog.iota), prog.iota)
+			value, err := assignField(conf, fv, field, field.Name, info.kinds[i], prog)
+			if err != nil {
+				conf.current.failed = true
+				report(err, prog)
+				continue
 			}
-			tf = tField
-		case tField != tf:
-			return reflect.Value{}, nil, invalidKind, errors.New("fields with different types")
+			conf.current.setValue(value)
 		}
 	}
-	return v, t, kind, nil
+	return errs
 }
 
-func assignStringEnums(v reflect.Value, t reflect.Type) {
-	for i := 0; i < v.NumField(); i++ {
-		tField := t.Field(i)
-		value, ok := tField.Tag.Lookup("fieldenum")
-		if !ok {
-			value = tField.Name
+// setIota sets iota of the field being assigned.
+// Rewritten expressions incrementing from a constant use index relative, which ignores the base of groups.
+func (conf *config) setIota(prog fieldProgram, iota int64, relative int) {
+	if prog.relative {
+		iota = int64(relative)
+	}
+	conf.Values["iota"] = iota
+}
+
+// assignBase evaluates the base of the group field, which is relative to base of the enclosing group.
+func assignBase(conf *config, field reflect.StructField, prog fieldProgram, base int64) (int64, *FieldError) {
+	if prog.Program == nil && prog.err == nil && prog.tagErr == nil {
+		return base, nil
+	}
+	v := reflect.New(reflect.TypeOf(base)).Elem()
+	if _, err := assignField(conf, v, field, field.Name, intKind, prog); err != nil {
+		return 0, err
+	}
+	return base + v.Int(), nil
+}
+
+// assignField evaluates the expression of the field, or an element of the array field, named name,
+// and returns the assigned value which can be referenced by other fields.
+func assignField(conf *config, v reflect.Value, field reflect.StructField, name string, kind uint8, prog fieldProgram) (_ any, fieldErr *FieldError) {
+	// enum numbers are assigned with the same rules as int fields
+	target, targetKind := v, kind
+	if kind == enumKind {
+		target, targetKind = reflect.New(reflect.TypeOf(0)).Elem(), intKind
+	}
+	var expr string // evaluated expression
+	if conf.explain {
+		defer func() { conf.explainField(name, expr, target, fieldErr) }()
+	}
+
+	wrapErr := newFieldError(name)
+	if prog.tagErr != nil {
+		return nil, wrapErr.setErr(prog.tagErr)
+	}
+	if kind == stringKind && (!conf.stringExpr || prog.Program == nil && prog.err == nil) {
+		v.SetString(prog.text)
+		return v.String(), nil
+	}
+
+	conf.name = field.Name
+
+	if prog.err != nil {
+		return nil, prog.wrapErr(wrapErr, prog.err)
+	}
+
+	expr = prog.Expr
+	value, err := prog.Eval(conf.Config)
+	if err != nil {
+		return nil, prog.wrapErr(wrapErr, err)
+	}
+
+	set := fieldSetStrict
+	switch {
+	case conf.Exact:
+		set = fieldSetExact
+	case conf.lenient:
+		set = fieldSetValue
+	}
+	if kind == stringKind {
+		value = conf.formatNumber(value)
+	}
+	err = set(target, value, targetKind)
+	if err == nil && kind == enumKind && !conf.check {
+		err = conf.addEnum(v, field.Name, int(target.Int()))
+	}
+	if err != nil {
+		return nil, prog.wrapErr(wrapErr, err)
+	}
+	return fieldNumber(target, targetKind, conf.Exact), nil
+}
+
+// addEnum checks that the enum.Enum field v named name can be registered,
+// and defers the registration until all fields are assigned.
+func (conf *config) addEnum(v reflect.Value, name string, number int) error {
+	pending := pendingEnum{v: v, name: conf.group + name, enum: name, number: number}
+	existed := func() error {
+		// the same error as enum.Init, which names type T of enum.Enum[T] returned by its Value method
+		typ := v.MethodByName("Value").Type().Out(0)
+		return fmt.Errorf(`Enum[%s] with existed name "%s"`, typ, pending.enum)
+	}
+	// fields of groups of the same enum type may have the same name
+	for _, other := range conf.enums {
+		if other.v.Type() == v.Type() && other.enum == pending.enum {
+			return existed()
+		}
+	}
+	// an enum name is registered if it can be unmarshaled
+	probe := reflect.New(v.Type()).Interface().(encoding.TextUnmarshaler)
+	if probe.UnmarshalText([]byte(pending.enum)) == nil {
+		return existed()
+	}
+	conf.enums = append(conf.enums, pending)
+	return nil
+}
+
+// registerEnums registers the enum.Enum fields added by addEnum.
+func (conf *config) registerEnums() []*FieldError {
+	var errs []*FieldError
+	for _, pending := range conf.enums {
+		if err := enum.Init(pending.v.Addr().Interface(), pending.enum, enum.WithNumber(pending.number)); err != nil {
+			errs = append(errs, newFieldError(pending.name).setErr(err))
 		}
-		v.Field(i).SetString(value)
 	}
+	conf.enums = nil
+	return errs
 }
 
-func assignNumberEnums(conf *config, v reflect.Value, t reflect.Type, kind uint8) error {
+// formatNumber formats numeric results of string fields by the formats of options.
+// Other results and results without formats are returned as is.
+func (conf *config) formatNumber(value any) any {
+	if conf.intFormat == "" && conf.floatFormat == "" && conf.formatter == nil {
+		return value
+	}
+
+	// exact results are formatted as int64 if possible, otherwise *big.Int or float64
+	number := value
+	switch x := value.(type) {
+	case *big.Rat:
+		if !x.IsInt() {
+			number, _ = x.Float64()
+			break
+		}
+		number = x.Num()
+		if x.Num().IsInt64() {
+			number = x.Num().Int64()
+		}
+	case *big.Int:
+		if x.IsInt64() {
+			number = x.Int64()
+		}
+	}
+
+	switch number.(type) {
+	case int64, *big.Int:
+		if conf.formatter != nil {
+			return conf.formatter(number)
+		}
+		if conf.intFormat != "" {
+			return fmt.Sprintf(conf.intFormat, number)
+		}
+	case float64, complex128:
+		if conf.formatter != nil {
+			return conf.formatter(number)
+		}
+		if conf.floatFormat != "" {
+			return fmt.Sprintf(conf.floatFormat, number)
+		}
+	}
+	return value
+}
+
+// fieldProgram is the compiled expression of a field.
+// The expression may be rewritten from the fieldenum tag,
+// offset converts columns of the expression to columns of the tag.
+// relative is true if the expression is rewritten to increment from a constant.
+// iota of the field doesn't count fields skipped by tag options, and restarts at reset.
+type fieldProgram struct {
+	*engine.Program
+	err      error
+	tagErr   error // error of tag options
+	tag      string
+	offset   int
+	relative bool
+	iota     int
+	text     string // verbatim value of string fields
+	ignore   bool   // the field is left untouched
+}
+
+// wrapErr sets err to wrapErr, with the tag and the column range of the invalid part of the tag if known.
+func (prog fieldProgram) wrapErr(wrapErr *FieldError, err error) *FieldError {
+	wrapErr.setErr(err)
+	var exprErr *ExprError
+	if prog.tag == "" || !errors.As(err, &exprErr) {
+		return wrapErr
+	}
+
+	start, end := exprErr.Columns()
+	clamp := func(column int) int {
+		if column += prog.offset; column < 1 {
+			return 1
+		} else if column > len(prog.tag)+1 {
+			return len(prog.tag) + 1
+		}
+		return column
+	}
+	return wrapErr.setExpr(prog.tag, clamp(start), clamp(end))
+}
+
+// compileFields compiles the expressions of fields of type t.
+// Numeric fields without expression share the program of the previous numeric field,
+// and string fields without expression share the program of the previous string field,
+// except fields with the tag option skip. Tags of string fields other than "-" are never parsed as options. Array fields are numeric fields,
+// and group fields have the program of their base or none.
+func compileFields(t reflect.Type, kinds []uint8) []fieldProgram {
 	var (
-		fset *token.FileSet
-		tree ast.Node
-		err  error
-		tab  string
-		expr string
-		ok   bool
+		numProg fieldProgram
+		numSeq  *sequence // sequence of numProg, nil if numProg isn't rewritten
+		strProg fieldProgram
+		index   int // iota of the field
 	)
 
-	for i := 0; i < v.NumField(); i++ {
-		conf.values["iota"] = int64(i)
+	progs := make([]fieldProgram, len(kinds))
+	for i, kind := range kinds {
 		field := t.Field(i)
-		wrapErr := newFieldError(field.Name)
-
-		tab, ok = field.Tag.Lookup("fieldenum")
-		if ok {
-			if expr = strings.TrimSpace(tab); tab == "" {
-				expr = "iota-" + strconv.Itoa(i)
-			} else if !strings.Contains(expr, "iota") {
-				expr = fmt.Sprintf("(iota-%d)+(%s)", i, expr)
-			} else {
-				expr = tab
-			}
+		tag, ok := field.Tag.Lookup("fieldenum")
+		var opts tagOptions
+		var err error
+		switch {
+		case ok && kind == stringKind && tag == "-":
+			opts = tagOptions{ignore: true}
+		case ok && kind == stringKind:
+			// tags of string fields were always valid values, so they can't start with options
+			opts = tagOptions{expr: tag, hasExpr: true}
+		case ok:
+			opts, err = parseTag(tag)
+		}
+		if err == nil {
+			err = checkOptions(kind, opts)
+		}
+		if opts.reset {
+			index = 0
+		}
 
-			if fset, tree, err = parse(expr); err != nil {
-				return wrapErr.setErr(err)
+		var prog fieldProgram
+		switch {
+		case err != nil:
+			prog.tag = tag
+		case opts.ignore:
+			prog.ignore = true
+		case kind == stringKind:
+			prog = strProg
+			prog.text = field.Name
+			if opts.hasExpr {
+				prog = compileString(tag, opts)
+				if !opts.skip {
+					strProg = prog
+				}
+			}
+		case kind == groupKind:
+			if opts.hasBase {
+				prog = compileBase(tag, opts.base, opts.baseOffset)
+			}
+		default:
+			cur, curSeq := numProg, numSeq
+			switch {
+			case opts.hasExpr:
+				if expr := strings.TrimSpace(opts.expr); opts.expr == "" || !strings.Contains(expr, "iota") {
+					if opts.expr == "" {
+						expr = "0"
+					}
+					curSeq = &sequence{expr: expr, tag: tag, offset: opts.exprOffset + leadingSpaces(opts.expr), anchor: index, step: 1}
+					if opts.step != 0 {
+						curSeq.step = opts.step
+					}
+					cur = curSeq.compile()
+				} else if opts.step != 0 {
+					err = errStepWithIota
+				} else {
+					cur, curSeq = fieldProgram{tag: tag, offset: opts.exprOffset}, nil
+					cur.Program, cur.err = engine.Parse(opts.expr)
+				}
+			case (opts.reset || opts.step != 0) && numSeq != nil:
+				curSeq = numSeq.restart(index, opts.reset, opts.step)
+				cur = curSeq.compile()
+			case opts.step != 0:
+				err = errStepWithIota
+			case numProg.Program == nil && numProg.err == nil:
+				numProg.Program, numProg.err = engine.Parse("iota")
+				cur = numProg
 			}
-		} else if i == 0 {
-			expr = "iota"
-			if fset, tree, err = parse(expr); err != nil {
-				return wrapErr.setErr(err)
+			if !opts.skip {
+				numProg, numSeq = cur, curSeq
 			}
+			prog = cur
 		}
 
-		value, err := eval(conf, tab, fset, tree)
-		if err != nil {
-			return wrapErr.setErr(err)
+		prog.iota, prog.tagErr = index, err
+		progs[i] = prog
+		if !opts.skip && !opts.ignore {
+			index++
 		}
+	}
+	return progs
+}
 
-		if err = fieldSetValue(v.Field(i), value, kind); err != nil {
-			return wrapErr.setErr(err)
-		}
+// checkOptions reports tag options which are invalid for fields of kind.
+func checkOptions(kind uint8, opts tagOptions) error {
+	switch {
+	case opts.ignore:
+		return nil
+	case kind == groupKind && opts.hasExpr:
+		return fmt.Errorf(`invalid group tag "%s", want "base=expr"`, opts.expr)
+	case kind != groupKind && opts.hasBase:
+		return errors.New(`tag option "base" is only for group fields`)
+	case opts.step != 0 && (kind == groupKind || kind == stringKind):
+		return errors.New(`tag option "step" is only for numeric fields`)
 	}
 	return nil
 }
 
+// sequence is a rewritten expression without iota, whose results increment by step
+// from the field of iota anchor, so that fields without expression continue the sequence.
+type sequence struct {
+	expr   string // trimmed expression
+	tag    string
+	offset int // offset of expr in the tag
+	anchor int
+	step   int64
+	shift  int64 // increment before the anchor, which is moved when the step changes
+}
+
+// compile rewrites the expression to "(iota-anchor)*step+shift+(expr)".
+func (s *sequence) compile() fieldProgram {
+	prefix := fmt.Sprintf("(iota-%d)+(", s.anchor)
+	if s.step != 1 || s.shift != 0 {
+		prefix = fmt.Sprintf("(iota-%d)*%d+%d+(", s.anchor, s.step, s.shift)
+	}
+	prog := fieldProgram{tag: s.tag, offset: s.offset - len(prefix), relative: true}
+	prog.Program, prog.err = engine.ParseWrapped(prefix, s.expr, ")")
+	return prog
+}
+
+// restart returns the sequence continued from the field of iota index with a new step,
+// or restarted from the expression if reset.
+func (s *sequence) restart(index int, reset bool, step int64) *sequence {
+	restarted := *s
+	if reset {
+		restarted.anchor, restarted.shift = 0, 0
+	} else {
+		restarted.shift += int64(index-s.anchor) * s.step
+		restarted.anchor = index
+	}
+	if step != 0 {
+		restarted.step = step
+	}
+	return &restarted
+}
+
+// compileString compiles the expression of a string field,
+// the expression is used verbatim as the value without [WithStringExpr].
+func compileString(tag string, opts tagOptions) fieldProgram {
+	expr := strings.TrimSpace(opts.expr)
+	if expr == "" {
+		expr = `""`
+	}
+	prog := fieldProgram{tag: tag, offset: opts.exprOffset + leadingSpaces(opts.expr), text: opts.expr}
+	prog.Program, prog.err = engine.Parse(expr)
+	return prog
+}
+
+// compileBase compiles the tag option "base=expr" of a group field.
+func compileBase(tag, expr string, offset int) fieldProgram {
+	prog := fieldProgram{tag: tag, offset: offset + leadingSpaces(expr)}
+	prog.Program, prog.err = engine.Parse(strings.TrimSpace(expr))
+	return prog
+}
+
+func leadingSpaces(s string) int { return len(s) - len(strings.TrimLeftFunc(s, unicode.IsSpace)) }
+
+func (ref *fieldRef) setValue(value any) { ref.value, ref.assigned = value, true }
+
+// fieldNumber returns the value of an assigned field as int64, float64, complex128 or string,
+// so that fields of named types can be referenced like fields of their underlying types.
+// In exact arithmetic, unsigned integers are returned as *big.Int, so that they don't overflow.
+func fieldNumber(v reflect.Value, kind uint8, exact bool) any {
+	switch kind {
+	case intKind:
+		return v.Int()
+	case uintKind:
+		if exact {
+			return new(big.Int).SetUint64(v.Uint())
+		}
+		return engine.ConvertToNumber(v.Uint())
+	case floatKind:
+		return v.Float()
+	case complexKind:
+		return v.Complex()
+	case stringKind:
+		return v.String()
+	}
+	return engine.ConvertToNumber(v.Interface())
+}
+
 func fieldSetValue(v reflect.Value, value any, kind uint8) error {
+	if val, ok := value.(bool); ok && kind != stringKind {
+		return fmt.Errorf(`assign boolean value "%v" to type %s, convert it by int() or float()`, val, v.Type().String())
+	}
+
 	var hasTypeErr bool
 	switch kind {
 	case intKind:
@@ -301,6 +967,8 @@ func fieldSetValue(v reflect.Value, value any, kind uint8) error {
 			v.SetString(strconv.FormatFloat(val, 'f', -1, 64))
 		case complex128:
 			v.SetString(strconv.FormatComplex(val, 'f', -1, 128))
+		case bool:
+			v.SetString(strconv.FormatBool(val))
 		default:
 			hasTypeErr = true
 		}
@@ -313,3 +981,172 @@ func fieldSetValue(v reflect.Value, value any, kind uint8) error {
 	}
 	return nil
 }
+
+// fieldSetStrict assigns the result after checking overflow and precision loss.
+func fieldSetStrict(v reflect.Value, value any, kind uint8) error {
+	if err := fieldCheckValue(v, value, kind); err != nil {
+		return err
+	}
+	return fieldSetValue(v, value, kind)
+}
+
+// fieldCheckValue reports an error if value is out of range of the field type,
+// or if value can't be converted to the field type without precision loss.
+// Float values must be represented exactly by 32-bit float fields, like integer values by float fields.
+// Negative values for unsigned fields and values of invalid types are reported by fieldSetValue.
+func fieldCheckValue(v reflect.Value, value any, kind uint8) error {
+	overflowErr := fmt.Errorf(`value "%v" overflows type %s`, value, v.Type().String())
+	truncatedErr := fmt.Errorf(`value "%v" truncated to type %s`, value, v.Type().String())
+	roundedErr := fmt.Errorf(`value "%v" rounded by type %s`, value, v.Type().String())
+
+	if c, ok := value.(complex128); ok && kind != complexKind && kind != stringKind {
+		if imag(c) != 0 {
+			return truncatedErr
+		}
+		value = real(c)
+	}
+
+	switch kind {
+	case intKind:
+		switch val := value.(type) {
+		case int64:
+			if v.OverflowInt(val) {
+				return overflowErr
+			}
+		case float64:
+			switch {
+			case math.IsNaN(val) || val < math.MinInt64 || val >= -math.MinInt64:
+				return overflowErr
+			case val != math.Trunc(val):
+				return truncatedErr
+			case v.OverflowInt(int64(val)):
+				return overflowErr
+			}
+		}
+	case uintKind:
+		switch val := value.(type) {
+		case int64:
+			if val >= 0 && v.OverflowUint(uint64(val)) {
+				return overflowErr
+			}
+		case float64:
+			switch {
+			case math.IsNaN(val) || val >= 1<<64:
+				return overflowErr
+			case val != math.Trunc(val):
+				return truncatedErr
+			case val >= 0 && v.OverflowUint(uint64(val)):
+				return overflowErr
+			}
+		}
+	case floatKind, complexKind:
+		bits := v.Type().Bits()
+		if kind == complexKind {
+			bits /= 2
+		}
+		parts := []any{value}
+		if c, ok := value.(complex128); ok {
+			parts = []any{real(c), imag(c)}
+		}
+		for _, part := range parts {
+			switch val := part.(type) {
+			case int64:
+				f := float64(val)
+				if bits == 32 {
+					f = float64(float32(f))
+				}
+				if i, acc := big.NewFloat(f).Int64(); i != val || acc != big.Exact {
+					return roundedErr
+				}
+			case float64:
+				switch {
+				case bits != 32:
+				case !math.IsInf(val, 0) && math.Abs(val) > math.MaxFloat32:
+					return overflowErr
+				case !math.IsNaN(val) && float64(float32(val)) != val:
+					return roundedErr
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// fieldSetExact assigns the result of exact arithmetic,
+// it reports an error if the result doesn't fit the field type exactly.
+func fieldSetExact(v reflect.Value, value any, kind uint8) error {
+	if c, ok := value.(complex128); ok && imag(c) == 0 && kind != complexKind && kind != stringKind {
+		value = real(c)
+	}
+	x, ok := engine.ExactNumber(value)
+	if !ok {
+		switch value.(type) {
+		case float64: // NaN or infinities
+			if kind == intKind || kind == uintKind {
+				return fmt.Errorf(`value "%v" overflows type %s`, value, v.Type().String())
+			}
+		case complex128:
+			if kind != complexKind && kind != stringKind {
+				return fmt.Errorf(`value "%v" truncated to type %s`, value, v.Type().String())
+			}
+		}
+		return fieldSetStrict(v, value, kind)
+	}
+
+	r, ok := x.(*big.Rat)
+	if !ok {
+		r = new(big.Rat).SetInt(x.(*big.Int))
+	}
+	overflowErr := fmt.Errorf(`value "%s" overflows type %s`, r.RatString(), v.Type().String())
+	truncatedErr := fmt.Errorf(`value "%s" truncated to type %s`, r.RatString(), v.Type().String())
+	roundedErr := fmt.Errorf(`value "%s" rounded by type %s`, r.RatString(), v.Type().String())
+
+	switch kind {
+	case intKind:
+		if !r.IsInt() {
+			return truncatedErr
+		}
+		if n := r.Num(); !n.IsInt64() || v.OverflowInt(n.Int64()) {
+			return overflowErr
+		}
+		v.SetInt(r.Num().Int64())
+	case uintKind:
+		if !r.IsInt() {
+			return truncatedErr
+		}
+		if n := r.Num(); !n.IsUint64() || v.OverflowUint(n.Uint64()) {
+			return overflowErr
+		}
+		v.SetUint(r.Num().Uint64())
+	case floatKind, complexKind:
+		bits := v.Type().Bits()
+		if kind == complexKind {
+			bits /= 2
+		}
+		// fractions are rounded like float64 results, but float32 fields must keep their float64 value
+		f, exact := r.Float64()
+		if bits == 32 {
+			f32, exact32 := r.Float32()
+			if !math.IsInf(float64(f32), 0) && float64(f32) != f {
+				return roundedErr
+			}
+			f, exact = float64(f32), exact32
+		}
+		if math.IsInf(f, 0) {
+			return overflowErr
+		}
+		if r.IsInt() && !exact {
+			return roundedErr
+		}
+		if kind == floatKind {
+			v.SetFloat(f)
+		} else {
+			v.SetComplex(complex(f, 0))
+		}
+	case stringKind:
+		v.SetString(r.RatString())
+	default:
+		return fieldSetValue(v, value, kind)
+	}
+	return nil
+}
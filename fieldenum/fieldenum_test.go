@@ -1,8 +1,16 @@
 package fieldenum
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/QAQandOwO/godget/enum"
+	"github.com/QAQandOwO/godget/fieldenum/internal/engine"
 )
 
 type (
@@ -138,6 +146,15 @@ type (
 		A string
 		B int
 	}
+	mixedEnum struct {
+		A int     `fieldenum:"100"`
+		B float64 `fieldenum:"iota/4.0"`
+		C string  `fieldenum:"c"`
+		D uint8
+		E complex64 `fieldenum:"iota*i"`
+		F string
+		G int8 `fieldenum:"-iota"`
+	}
 	invalidExprEnum struct {
 		A uint `fieldenum:"-1"`
 	}
@@ -152,6 +169,174 @@ type (
 		C int `fieldenum:"fc(iota)"`
 	}
 
+	refEnum struct {
+		Read      uint8 `fieldenum:"1<<iota"`
+		Write     uint8
+		Exec      uint8
+		ReadWrite uint8 `fieldenum:"Read|Write"`
+		All       uint8 `fieldenum:"ReadWrite|Exec"`
+		Base      uint8 `fieldenum:"All*2"`
+		Next      uint8
+	}
+	// references to fields of named types are converted by their underlying types
+	refNamedEnum struct {
+		Code   namedCode  `fieldenum:"200"`
+		Flags  namedUint  `fieldenum:"Code / 100"`
+		Ratio  namedFloat `fieldenum:"Flags / 4.0"`
+		Signal namedCode  `fieldenum:"Code + Flags*2 + Ratio*4"`
+	}
+	forwardRefEnum struct {
+		A int `fieldenum:"B"`
+		B int
+	}
+	selfRefEnum struct {
+		A int
+		B int `fieldenum:"B+1"`
+	}
+
+	condEnum struct {
+		A int `fieldenum:"if(iota < 2, iota, iota*10)"`
+		B int
+		C int
+		D int `fieldenum:"int(C == 20 && B == 1)"`
+	}
+	boolEnum struct {
+		A int `fieldenum:"iota == 0"`
+	}
+
+	stringExprEnum struct {
+		StatusOK      string `fieldenum:"\"status_\" + snake(name)"`
+		NotFound      string
+		InternalError string `fieldenum:"upper(kebab(name))"`
+		Code          int    `fieldenum:"100"`
+		CodeName      string `fieldenum:"fmt(\"%s_%d\", lower(name), Code)"`
+		Empty         string `fieldenum:""`
+		Named         string `fieldenum:"name"`
+	}
+
+	exactEnum struct {
+		A uint64 `fieldenum:"1<<63"`
+		B uint64
+		C uint64  `fieldenum:"1<<64 - 1"`
+		D int64   `fieldenum:"A - 1"`
+		E int8    `fieldenum:"pow(2, 7) - 1"`
+		F float32 `fieldenum:"0.25"`
+		G int     `fieldenum:"7/2.0 * 2"`
+		H string  `fieldenum:"x"`
+	}
+	exactOverflowEnum struct {
+		A int8 `fieldenum:"128"`
+	}
+	exactTruncatedEnum struct {
+		A int `fieldenum:"7/2.0"`
+	}
+
+	narrowEnum struct {
+		A int8 `fieldenum:"127"`
+		B int8
+	}
+	nanEnum struct {
+		A int `fieldenum:"nan"`
+	}
+	roundedEnum struct {
+		A float32 `fieldenum:"1<<24 + 1"`
+	}
+	// float results are rounded by float32 fields only with WithLenientAssignment
+	roundedFloatEnum struct {
+		A float32   `fieldenum:"0.1"`
+		B complex64 `fieldenum:"0.1 + 0.2i"`
+		C float32   `fieldenum:"1e39"`
+	}
+
+	groupEnum struct {
+		OK   int
+		Auth struct {
+			Expired int
+			Revoked int
+			Locked  int `fieldenum:"Revoked + 10"`
+		} `fieldenum:"base=1000"`
+		DB struct {
+			Timeout int
+			Session struct {
+				Lost  int
+				Limit int `fieldenum:"OK + Timeout"`
+			} `fieldenum:"base=100"`
+		} `fieldenum:"base=2000"`
+		Unknown int
+	}
+	arrayEnum struct {
+		A     int
+		Flags [3]uint8 `fieldenum:"1 << iota"`
+		B     int
+		Steps [4]float64 `fieldenum:"0.5"`
+		Zero  [2]int     `fieldenum:""`
+	}
+	invalidBaseEnum struct {
+		A struct{ B int } `fieldenum:"start=1"`
+	}
+	invalidGroupEnum struct {
+		A struct{ b int }
+	}
+	invalidArrayEnum struct {
+		A [2]string
+	}
+
+	optionEnum struct {
+		A int
+		B int `fieldenum:"expr=100,skip"`
+		C int
+		D int `fieldenum:"-"`
+		E int `fieldenum:"expr=10,step=5"`
+		F int
+		G int `fieldenum:"step=2"`
+		H int
+		I int `fieldenum:"reset"`
+		J int
+		K int `fieldenum:"expr=iota*iota,reset"`
+		L int
+		M int
+		S string `fieldenum:"expr=max(1, 2),skip"`
+		N int    `fieldenum:"expr=max(1, 2) + 1"`
+		O string
+		P string `fieldenum:"-"`
+		Q int    `fieldenum:"iota"`
+	}
+	stringTagEnum struct {
+		A string `fieldenum:"-"`
+		B string `fieldenum:"skip"`
+		C string `fieldenum:"step=5"`
+		D int    `fieldenum:"iota"`
+	}
+	optionErrorEnum struct {
+		A int `fieldenum:"expr=1,foo"`
+		B int `fieldenum:"expr=iota,step=2"`
+		C int `fieldenum:"skip,skip"`
+		D int `fieldenum:"base=1"`
+		E int `fieldenum:"step=x"`
+		F int `fieldenum:"step=2"`
+	}
+
+	unitEnum struct {
+		Timeout  time.Duration `fieldenum:"30*s"`
+		Retry    time.Duration `fieldenum:"1500*ms"`
+		Session  time.Duration `fieldenum:"2*h + 30*min"`
+		Tick     time.Duration `fieldenum:"10*us"`
+		Next     time.Duration
+		MaxBody  int64   `fieldenum:"4*MiB"`
+		MaxDisk  uint64  `fieldenum:"1.5*TiB"`
+		Lowest   int     `fieldenum:"min(KiB, 1000)"`
+		Interval float64 `fieldenum:"h / s"`
+	}
+
+	formatEnum struct {
+		Code   string `fieldenum:"0x10 + iota"`
+		Next   string
+		Ratio  string `fieldenum:"1/3.0"`
+		Tiny   string `fieldenum:"1.5e-10"`
+		Signal string `fieldenum:"1i + 1"`
+		Text   string `fieldenum:"\"text\""`
+	}
+
 	fieldEnumTester interface {
 		gotEnums() any
 		assert(value any) bool
@@ -159,14 +344,17 @@ type (
 		wantPanic() bool
 	}
 	fieldEnumTest[T any] struct {
-		want   any
-		values map[string]any
-		funcs  map[string]ExprFunc
-		panic  bool
+		want    any
+		values  map[string]any
+		funcs   map[string]ExprFunc
+		options []Option
+		panic   bool
 	}
 )
 
-func (t fieldEnumTest[T]) gotEnums() any   { return New[T](WithValues(t.values), WithFuncs(t.funcs)) }
+func (t fieldEnumTest[T]) gotEnums() any {
+	return New[T](append([]Option{WithValues(t.values), WithFuncs(t.funcs)}, t.options...)...)
+}
 func (t fieldEnumTest[T]) wantPanic() bool { return t.panic }
 func (t fieldEnumTest[T]) wantValue() any  { return t.want }
 func (t fieldEnumTest[T]) assert(value any) bool {
@@ -306,6 +494,10 @@ func TestNew(t *testing.T) {
 			F: 5 + 5i,
 		},
 	}
+	groups := groupEnum{OK: 0, Unknown: 3}
+	groups.Auth.Expired, groups.Auth.Revoked, groups.Auth.Locked = 1000, 1001, 1011
+	groups.DB.Timeout, groups.DB.Session.Lost, groups.DB.Session.Limit = 2000, 2100, 2000
+	wants["groupEnum"] = groups
 
 	tests := []fieldEnumTester{
 		0:  fieldEnumTest[stringEnum]{want: wants["stringEnum"]},
@@ -342,7 +534,7 @@ func TestNew(t *testing.T) {
 		31: fieldEnumTest[*complex128Enum]{want: wants["complex128Enum"]},
 		32: fieldEnumTest[incomparableEnum]{panic: true},
 		33: fieldEnumTest[notSettableEnum]{panic: true},
-		34: fieldEnumTest[differentTypeEnum]{panic: true},
+		34: fieldEnumTest[differentTypeEnum]{want: differentTypeEnum{A: "A", B: 1}},
 		35: fieldEnumTest[invalidExprEnum]{panic: true},
 		36: fieldEnumTest[valuesEnum]{
 			want:   valuesEnum{A: 1, B: 3, C: 5},
@@ -352,12 +544,128 @@ func TestNew(t *testing.T) {
 		38: fieldEnumTest[funcsEnum]{
 			want: funcsEnum{A: 0, B: 1, C: 4},
 			funcs: map[string]ExprFunc{
-				"fa": func(values []any) (any, error) { x, _ := convertToNumber(values[0]).(int64); return x, nil },
-				"fb": func(values []any) (any, error) { x, _ := convertToNumber(values[0]).(int64); return x + 1, nil },
-				"fc": func(values []any) (any, error) { x, _ := convertToNumber(values[0]).(int64); return x + 2, nil },
+				"fa": func(values []any) (any, error) { x, _ := engine.ConvertToNumber(values[0]).(int64); return x, nil },
+				"fb": func(values []any) (any, error) { x, _ := engine.ConvertToNumber(values[0]).(int64); return x + 1, nil },
+				"fc": func(values []any) (any, error) { x, _ := engine.ConvertToNumber(values[0]).(int64); return x + 2, nil },
 			},
 		},
 		39: fieldEnumTest[funcsEnum]{panic: true},
+		40: fieldEnumTest[mixedEnum]{
+			want:    mixedEnum{A: 100, B: 0.25, C: "c", D: 0, E: 4i, F: "F", G: -6},
+			options: []Option{WithLenientAssignment()},
+		},
+		41: fieldEnumTest[refEnum]{want: refEnum{Read: 1, Write: 2, Exec: 4, ReadWrite: 3, All: 7, Base: 14, Next: 15}},
+		42: fieldEnumTest[forwardRefEnum]{panic: true},
+		43: fieldEnumTest[selfRefEnum]{panic: true},
+		44: fieldEnumTest[condEnum]{want: condEnum{A: 0, B: 1, C: 20, D: 1}},
+		45: fieldEnumTest[boolEnum]{panic: true},
+		46: fieldEnumTest[stringExprEnum]{
+			want: stringExprEnum{
+				StatusOK:      "status_status_ok",
+				NotFound:      "status_not_found",
+				InternalError: "INTERNAL-ERROR",
+				Code:          100,
+				CodeName:      "codename_100",
+				Empty:         "",
+				Named:         "Named",
+			},
+			options: []Option{WithStringExpr()},
+		},
+		47: fieldEnumTest[stringExprEnum]{
+			want: stringExprEnum{
+				StatusOK:      `"status_" + snake(name)`,
+				NotFound:      "NotFound",
+				InternalError: "upper(kebab(name))",
+				Code:          100,
+				CodeName:      `fmt("%s_%d", lower(name), Code)`,
+				Empty:         "",
+				Named:         "name",
+			},
+		},
+		48: fieldEnumTest[stringEnum]{want: stringEnum{A: "A", B: "A", C: "", D: "3"}, options: []Option{WithStringExpr()}},
+		49: fieldEnumTest[exactEnum]{
+			want: exactEnum{
+				A: 1 << 63,
+				B: 1<<63 + 1,
+				C: 1<<64 - 1,
+				D: 1<<63 - 1,
+				E: 127,
+				F: 0.25,
+				G: 7,
+				H: "x",
+			},
+			options: []Option{WithExactArithmetic()},
+		},
+		50: fieldEnumTest[exactEnum]{panic: true},
+		51: fieldEnumTest[exactOverflowEnum]{panic: true, options: []Option{WithExactArithmetic()}},
+		52: fieldEnumTest[exactTruncatedEnum]{panic: true, options: []Option{WithExactArithmetic()}},
+		53: fieldEnumTest[mixedEnum]{panic: true},
+		54: fieldEnumTest[narrowEnum]{panic: true},
+		55: fieldEnumTest[narrowEnum]{want: narrowEnum{A: 127, B: -128}, options: []Option{WithLenientAssignment()}},
+		56: fieldEnumTest[nanEnum]{panic: true},
+		57: fieldEnumTest[roundedEnum]{panic: true},
+		58: fieldEnumTest[roundedEnum]{want: roundedEnum{A: 1 << 24}, options: []Option{WithLenientAssignment()}},
+		59: fieldEnumTest[groupEnum]{want: wants["groupEnum"]},
+		60: fieldEnumTest[arrayEnum]{want: arrayEnum{
+			A:     0,
+			Flags: [3]uint8{1, 2, 4},
+			B:     4,
+			Steps: [4]float64{0.5, 1.5, 2.5, 3.5},
+			Zero:  [2]int{0, 1},
+		}},
+		61: fieldEnumTest[invalidBaseEnum]{panic: true},
+		62: fieldEnumTest[invalidGroupEnum]{panic: true},
+		63: fieldEnumTest[invalidArrayEnum]{panic: true},
+		64: fieldEnumTest[optionEnum]{want: optionEnum{
+			A: 0, B: 100, C: 1, D: 0,
+			E: 10, F: 15, G: 20, H: 22,
+			I: 10, J: 12, K: 0, L: 1, M: 4,
+			S: "expr=max(1, 2),skip", N: 3, O: "O", P: "", Q: 6,
+		}},
+		65: fieldEnumTest[optionErrorEnum]{panic: true},
+		66: fieldEnumTest[formatEnum]{
+			want:    formatEnum{Code: "16", Next: "17", Ratio: "0.3333333333333333", Tiny: "0.00000000015", Signal: "(1+1i)", Text: "text"},
+			options: []Option{WithStringExpr()},
+		},
+		67: fieldEnumTest[formatEnum]{
+			want:    formatEnum{Code: "0x0010", Next: "0x0011", Ratio: "0.333", Tiny: "1.5e-10", Signal: "(1+1i)", Text: "text"},
+			options: []Option{WithStringExpr(), WithIntFormat("0x%04X"), WithFloatFormat("%.3g")},
+		},
+		68: fieldEnumTest[formatEnum]{
+			want:    formatEnum{Code: "0b10000", Next: "0b10001", Ratio: "1/3", Tiny: "3/20000000000", Signal: "(1+1i)", Text: "text"},
+			options: []Option{WithStringExpr(), WithExactArithmetic(), WithIntFormat("%#b")},
+		},
+		69: fieldEnumTest[formatEnum]{
+			want:    formatEnum{Code: "0x0010", Next: "0x0011", Ratio: "3.33e-01", Tiny: "1.50e-10", Signal: "(1.00e+00+1.00e+00i)", Text: "text"},
+			options: []Option{WithStringExpr(), WithExactArithmetic(), WithIntFormat("0x%04x"), WithFloatFormat("%.2e")},
+		},
+		70: fieldEnumTest[formatEnum]{
+			want: formatEnum{Code: "int64", Next: "int64", Ratio: "float64", Tiny: "float64", Signal: "complex128", Text: "text"},
+			options: []Option{WithStringExpr(), WithIntFormat("%x"), WithFormatter(func(value any) string {
+				return fmt.Sprintf("%T", value)
+			})},
+		},
+		71: fieldEnumTest[formatEnum]{options: []Option{WithIntFormat("%s")}, panic: true},
+		72: fieldEnumTest[formatEnum]{options: []Option{WithFloatFormat("%.2f %d")}, panic: true},
+		73: fieldEnumTest[unitEnum]{
+			want: unitEnum{
+				Timeout: 30 * time.Second, Retry: 1500 * time.Millisecond, Session: 150 * time.Minute,
+				Tick: 10 * time.Microsecond, Next: 10*time.Microsecond + 1,
+				MaxBody: 4 << 20, MaxDisk: 3 << 39, Lowest: 1000, Interval: 3600,
+			},
+			options: []Option{WithUnits()},
+		},
+		74: fieldEnumTest[unitEnum]{panic: true},
+		75: fieldEnumTest[unitEnum]{options: []Option{WithValues(map[string]any{"s": 1}), WithUnits()}, panic: true},
+		76: fieldEnumTest[refNamedEnum]{want: refNamedEnum{Code: 200, Flags: 2, Ratio: 0.5, Signal: 206}},
+		77: fieldEnumTest[roundedFloatEnum]{panic: true},
+		78: fieldEnumTest[roundedFloatEnum]{
+			want:    roundedFloatEnum{A: 0.1, B: 0.1 + 0.2i, C: float32(math.Inf(1))},
+			options: []Option{WithLenientAssignment()},
+		},
+		79: fieldEnumTest[stringTagEnum]{want: stringTagEnum{A: "", B: "skip", C: "step=5", D: 2}},
+		80: fieldEnumTest[stringTagEnum]{panic: true, options: []Option{WithStringExpr()}},
+		81: fieldEnumTest[roundedEnum]{panic: true, options: []Option{WithExactArithmetic()}},
 	}
 
 	for i, test := range tests {
@@ -382,3 +690,411 @@ func TestNew(t *testing.T) {
 		}()
 	}
 }
+
+type enumLevel struct{}
+
+func TestNew_enum(t *testing.T) {
+	levels := New[struct {
+		Debug enum.Enum[enumLevel] `fieldenum:"-1"`
+		Info  enum.Enum[enumLevel]
+		Warn  enum.Enum[enumLevel]
+		Error enum.Enum[enumLevel] `fieldenum:"iota*10"`
+	}]()
+
+	wants := []struct {
+		enum   enum.Enum[enumLevel]
+		name   string
+		number int
+	}{
+		0: {enum: levels.Debug, name: "Debug", number: -1},
+		1: {enum: levels.Info, name: "Info", number: 0},
+		2: {enum: levels.Warn, name: "Warn", number: 1},
+		3: {enum: levels.Error, name: "Error", number: 30},
+	}
+	for i, want := range wants {
+		if got := want.enum; got.Name() != want.name || got.Number() != want.number {
+			t.Errorf("[%d]ERROR: got %s(%d), want %s(%d)", i, got.Name(), got.Number(), want.name, want.number)
+		}
+		if got, ok := enum.GetEnumByName[enumLevel](want.name); !ok || got != want.enum {
+			t.Errorf("[%d]ERROR: got %#v, want registered %#v", i, got, want.enum)
+		}
+	}
+
+	t.Run("existed name", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("ERROR: no panic, want panic")
+			}
+		}()
+		New[struct{ Info enum.Enum[enumLevel] }]()
+	})
+}
+
+type retryLevel struct{}
+
+func TestTryNew_enum(t *testing.T) {
+	type levels struct {
+		Debug enum.Enum[retryLevel]
+		Info  int8 `fieldenum:"info"`
+	}
+	if _, err := TryNew[levels](); err == nil {
+		t.Errorf("ERROR: got no error, want error of Info")
+	}
+	if _, ok := enum.GetEnumByName[retryLevel]("Debug"); ok {
+		t.Errorf("ERROR: got registered enum Debug of failing type, want not registered")
+	}
+	got, err := TryNew[levels](WithValues(map[string]any{"info": 1}))
+	if err != nil || got.Debug.Name() != "Debug" || got.Info != 1 {
+		t.Errorf("ERROR: got %v, %v, want registered Debug", got, err)
+	}
+
+	type groups struct {
+		A struct{ Warn enum.Enum[retryLevel] }
+		B struct{ Warn enum.Enum[retryLevel] }
+	}
+	want := `field "B.Warn": Enum[fieldenum.retryLevel] with existed name "Warn"`
+	if _, err := TryNew[groups](); err == nil || err.Error() != want {
+		t.Errorf("ERROR: got error %v, want %s", err, want)
+	}
+	if _, ok := enum.GetEnumByName[retryLevel]("Warn"); ok {
+		t.Errorf("ERROR: got registered enum Warn of failing type, want not registered")
+	}
+}
+
+func TestNew_error(t *testing.T) {
+	tests := []struct {
+		assign func() error
+		want   string
+	}{
+		0: {
+			assign: func() error { _, err := assign[forwardRefEnum](nil); return err },
+			want: `field "A": reference field "B" before assignment with expression "B"` + "\n" +
+				`field "B": reference field "B" itself with expression "B"`,
+		},
+		1: {
+			assign: func() error { _, err := assign[selfRefEnum](nil); return err },
+			want:   `field "B": reference field "B" itself with expression "B"`,
+		},
+		2: {
+			assign: func() error { _, err := assign[narrowEnum](nil); return err },
+			want:   `field "B": value "128" overflows type int8`,
+		},
+		3: {
+			assign: func() error { _, err := assign[mixedEnum](nil); return err },
+			want:   `field "D": value "0.75" truncated to type uint8`,
+		},
+		4: {
+			assign: func() error { _, err := assign[nestedErrorEnum](nil); return err },
+			want: `field "Auth.Expired": value "1000" overflows type int8` + "\n" +
+				`field "Flags[2]": value "256" overflows type uint8`,
+		},
+		5: {
+			assign: func() error { _, err := assign[invalidBaseEnum](nil); return err },
+			want:   `field "A": invalid group tag "start=1", want "base=expr"`,
+		},
+		6: {
+			assign: func() error { _, err := assign[invalidGroupEnum](nil); return err },
+			want:   `field "A.b": is not settable`,
+		},
+		7: {
+			assign: func() error { _, err := assign[optionErrorEnum](nil); return err },
+			want: `field "A": invalid tag option "foo"` + "\n" +
+				`field "B": tag option "step" requires an expression without iota` + "\n" +
+				`field "C": duplicate tag option "skip"` + "\n" +
+				`field "D": tag option "base" is only for group fields` + "\n" +
+				`field "E": invalid tag option "step=x", want a non-zero integer` + "\n" +
+				`field "F": tag option "step" requires an expression without iota`,
+		},
+		8: {
+			// 1<<63 overflows int64 without exact arithmetic
+			assign: func() error { _, err := assign[exactEnum](nil); return err },
+			want: `field "A": assgin negative value "-9223372036854775808" to type uint64` + "\n" +
+				`field "B": assgin negative value "-9223372036854775807" to type uint64` + "\n" +
+				`field "C": assgin negative value "-1" to type uint64`,
+		},
+		9: {
+			assign: func() error { _, err := assign[exactOverflowEnum]([]Option{WithExactArithmetic()}); return err },
+			want:   `field "A": value "128" overflows type int8`,
+		},
+		10: {
+			assign: func() error { _, err := assign[exactTruncatedEnum]([]Option{WithExactArithmetic()}); return err },
+			want:   `field "A": value "7/2" truncated to type int`,
+		},
+		11: {
+			assign: func() error { _, err := assign[roundedFloatEnum](nil); return err },
+			want: `field "A": value "0.1" rounded by type float32` + "\n" +
+				`field "B": value "(0.1+0.2i)" rounded by type complex64` + "\n" +
+				`field "C": value "1e+39" overflows type float32`,
+		},
+		12: {
+			assign: func() error { _, err := assign[roundedFloatEnum]([]Option{WithExactArithmetic()}); return err },
+			want: `field "A": value "1/10" rounded by type float32` + "\n" +
+				`field "B": value "(0.1+0.2i)" rounded by type complex64` + "\n" +
+				`field "C": value "1000000000000000000000000000000000000000" overflows type float32`,
+		},
+	}
+	for i, test := range tests {
+		if err := test.assign(); err == nil || err.Error() != test.want {
+			t.Errorf("[%d]ERROR: got %v, want %s", i, err, test.want)
+		}
+	}
+}
+
+type nestedErrorEnum struct {
+	Auth struct {
+		Expired int8
+	} `fieldenum:"base=1000"`
+	Flags [3]uint8 `fieldenum:"16 << (iota*2)"`
+}
+
+type multiErrorEnum struct {
+	A int8 `fieldenum:"200"`
+	B int8 `fieldenum:"A + 1"`
+	C int  `fieldenum:"  1 + foo(2)"`
+	D int
+	E int `fieldenum:"1 +"`
+	F int
+	G int `fieldenum:"iota"`
+}
+
+type namedCode int
+
+func TestNew_namedType(t *testing.T) {
+	got := New[struct {
+		OK       namedCode `fieldenum:"200"`
+		NotFound namedCode `fieldenum:"404"`
+		Teapot   namedCode `fieldenum:"NotFound + 14"`
+		Label    namedString
+		Copy     namedString `fieldenum:"Label + \"!\""`
+	}](WithStringExpr())
+	if got.Teapot != 418 || got.Copy != "Label!" {
+		t.Errorf("ERROR: got %v, want Teapot 418 and Copy Label!", got)
+	}
+}
+
+type (
+	namedString string
+	namedUint   uint16
+	namedFloat  float32
+)
+
+func TestNewValue(t *testing.T) {
+	typ := reflect.StructOf([]reflect.StructField{
+		{Name: "A", Type: reflect.TypeOf(0), Tag: `fieldenum:"10"`},
+		{Name: "B", Type: reflect.TypeOf(0)},
+	})
+	got, err := NewValue(typ)
+	if err != nil || got.Field(0).Int() != 10 || got.Field(1).Int() != 11 {
+		t.Errorf("ERROR: got %v, %v, want {10 11}", got, err)
+	}
+	if _, err = NewValue(reflect.TypeOf(0)); err == nil {
+		t.Errorf("ERROR: got no error, want error of invalid type")
+	}
+}
+
+func TestTryNew(t *testing.T) {
+	got, err := TryNew[intEnum]()
+	if err != nil || got != (intEnum{A: 0, B: 1, C: 3, D: 4, E: 16, F: 25}) {
+		t.Errorf("ERROR: got %v, %v, want no error", got, err)
+	}
+
+	got2, err := TryNew[multiErrorEnum]()
+	if got2 != (multiErrorEnum{}) {
+		t.Errorf("ERROR: got %v, want zero value", got2)
+	}
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("ERROR: got %T, want Errors", err)
+	}
+
+	wants := []struct {
+		name       string
+		tag        string
+		start, end int
+	}{
+		0: {name: "A"},
+		1: {name: "C", tag: "  1 + foo(2)", start: 7, end: 13},
+		2: {name: "E", tag: "1 +", start: 1, end: 4},
+	}
+	if len(errs) != len(wants) {
+		t.Fatalf("ERROR: got %d errors %v, want %d errors", len(errs), errs, len(wants))
+	}
+	for i, want := range wants {
+		var fieldErr *FieldError
+		if !errors.As(errs[i], &fieldErr) {
+			t.Errorf("[%d]ERROR: got %T, want *FieldError", i, errs[i])
+			continue
+		}
+		if fieldErr.Name != want.name || fieldErr.Tag != want.tag || fieldErr.Start != want.start || fieldErr.End != want.end {
+			t.Errorf("[%d]ERROR: got %s %q [%d, %d), want %s %q [%d, %d)", i,
+				fieldErr.Name, fieldErr.Tag, fieldErr.Start, fieldErr.End,
+				want.name, want.tag, want.start, want.end)
+		}
+	}
+
+	var funcErr *FuncError
+	if !errors.As(errs[1], &funcErr) || funcErr.Func != "foo" {
+		t.Errorf("ERROR: got %v, want *FuncError of foo", funcErr)
+	}
+	var exprErr *ExprError
+	if !errors.As(errs[2], &exprErr) {
+		t.Errorf("ERROR: got %v, want *ExprError", errs[2])
+	}
+
+	// the methods don't rely on unwrapping multiple errors, which requires Go 1.20
+	var firstErr *FieldError
+	if !errs.As(&firstErr) || firstErr != errs[0] || !errors.As(err, &firstErr) {
+		t.Errorf("ERROR: got %v, want the *FieldError of A", firstErr)
+	}
+	if !errs.Is(errs[2]) || !errs.Is(funcErr) || errs.Is(errFailedField) {
+		t.Errorf("ERROR: Is doesn't match the errors of %v", errs)
+	}
+
+	if _, err = TryNew[notSettableEnum](); err == nil {
+		t.Errorf("ERROR: got no error, want error")
+	}
+}
+
+type limitEnum struct {
+	A int `fieldenum:"1 + 2*3"`
+	B int
+	C int `fieldenum:"abs(-1)"`
+	D int `fieldenum:"if(true, 1, 2)"`
+}
+
+func TestTryNew_limits(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		options []Option
+		want    limitEnum
+		errs    []string
+	}{
+		0: {options: []Option{WithMaxDepth(3), WithMaxNodes(5), WithAllowedFuncs("abs")}, want: limitEnum{A: 7, B: 8, C: 1, D: 1}},
+		1: {
+			options: []Option{WithMaxDepth(2)},
+			errs: []string{
+				`field "A": expression depth 3 exceeds limit 2 with expression "1 + 2*3"`,
+				`field "C": expression depth 3 exceeds limit 2 with expression "abs(-1)"`,
+			},
+		},
+		2: {
+			options: []Option{WithMaxNodes(4)},
+			errs:    []string{`field "A": expression of 5 nodes exceeds limit 4 with expression "1 + 2*3"`},
+		},
+		3: {
+			options: []Option{WithAllowedFuncs("max"), WithAllowedFuncs()},
+			errs:    []string{`field "C": call disallowed function "abs" with expression "abs(-1)"`},
+		},
+		4: {
+			options: []Option{WithContext(canceled)},
+			errs: []string{
+				`field "A": evaluation stopped: context canceled with expression "1 + 2*3"`,
+				`field "C": evaluation stopped: context canceled with expression "abs(-1)"`,
+				`field "D": evaluation stopped: context canceled with expression "if(true, 1, 2)"`,
+			},
+		},
+	}
+	for i, test := range tests {
+		got, err := TryNew[limitEnum](test.options...)
+		if test.errs == nil {
+			if err != nil || got != test.want {
+				t.Errorf("[%d]ERROR: got %v, %v, want %v", i, got, err, test.want)
+			}
+			continue
+		}
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != len(test.errs) {
+			t.Errorf("[%d]ERROR: got %v, want %d errors", i, err, len(test.errs))
+			continue
+		}
+		for j, want := range test.errs {
+			var limitErr *LimitError
+			if !errors.As(errs[j], &limitErr) || errs[j].Error() != want {
+				t.Errorf("[%d]ERROR: got %v, want %s", i, errs[j], want)
+			}
+		}
+	}
+}
+
+func TestFieldError_Diagnostic(t *testing.T) {
+	_, err := TryNew[multiErrorEnum]()
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("ERROR: got %v, want 3 errors", err)
+	}
+
+	wants := []string{
+		0: `multiErrorEnum.A: value "200" overflows type int8`,
+		1: "multiErrorEnum.C: call non-existed function \"foo\"\n\t  1 + foo(2)\n\t      ^~~~~~",
+		2: "multiErrorEnum.E: invalid expression\n\t1 +\n\t^~~",
+	}
+	for i, want := range wants {
+		if got := errs[i].(*FieldError).Diagnostic(); got != want {
+			t.Errorf("[%d]ERROR: got %q, want %q", i, got, want)
+		}
+	}
+
+	_, err = TryNew[struct {
+		A int8 `fieldenum:"128"`
+	}]()
+	if errors.As(err, &errs); errs[0].(*FieldError).Diagnostic() != `struct{...}.A: value "128" overflows type int8` {
+		t.Errorf("ERROR: got %q, want anonymous struct", errs[0].(*FieldError).Diagnostic())
+	}
+
+	_, err = TryNew[struct {
+		A int `fieldenum:"skip,expr= 1 + foo(2)"`
+	}]()
+	want := "struct{...}.A: call non-existed function \"foo\"\n\tskip,expr= 1 + foo(2)\n\t               ^~~~~~"
+	if errors.As(err, &errs); errs[0].(*FieldError).Diagnostic() != want {
+		t.Errorf("ERROR: got %q, want %q", errs[0].(*FieldError).Diagnostic(), want)
+	}
+}
+
+type checkLevel struct{}
+
+func TestCheck(t *testing.T) {
+	type levels struct {
+		Debug enum.Enum[checkLevel] `fieldenum:"-1"`
+		Info  enum.Enum[checkLevel]
+	}
+	if err := Check(reflect.TypeOf(levels{})); err != nil {
+		t.Errorf("ERROR: got %v, want no error", err)
+	}
+	if _, ok := enum.GetEnumByName[checkLevel]("Debug"); ok {
+		t.Errorf("ERROR: got registered enum, want no registration")
+	}
+	New[levels]()
+
+	if err := Check(reflect.TypeOf(multiErrorEnum{})); err == nil {
+		t.Errorf("ERROR: got no error, want error")
+	}
+	if err := Check(reflect.TypeOf(0)); err == nil {
+		t.Errorf("ERROR: got no error, want error")
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.Run("string", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			New[stringEnum]()
+		}
+	})
+	b.Run("int", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			New[intEnum]()
+		}
+	})
+	b.Run("reference", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			New[refEnum]()
+		}
+	})
+	b.Run("values", func(b *testing.B) {
+		values := map[string]any{"a": 1, "b": 2, "c": 3}
+		for i := 0; i < b.N; i++ {
+			New[valuesEnum](WithValues(values))
+		}
+	})
+}
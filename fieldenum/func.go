@@ -0,0 +1,121 @@
+package fieldenum
+
+import (
+	"reflect"
+
+	"github.com/QAQandOwO/godget/fieldenum/internal/engine"
+)
+
+// Number is the constraint of numeric types.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 |
+		~complex64 | ~complex128
+}
+
+// Scalar is the constraint of argument and result types of functions adapted by [Func1], [Func2] and [FuncVariadic].
+type Scalar interface {
+	Number | ~string | ~bool
+}
+
+// Func1 adapts a function of one argument to an [ExprFunc].
+//
+// Arguments are converted to type T if they fit without precision loss,
+// so integers are promoted to floats and complex numbers, and floats without fractional part to integers.
+// Otherwise, or for calls with a wrong number of arguments, the adapted function returns an error.
+// Results are converted to int64, float64 or complex128 like the results of built-in functions.
+//
+//	fieldenum.WithFuncs(map[string]fieldenum.ExprFunc{
+//		"floor": fieldenum.Func1[float64](math.Floor),
+//	})
+func Func1[T, R Scalar](fn func(T) R) ExprFunc {
+	return func(values []any) (any, error) {
+		if len(values) != 1 {
+			return nil, &FuncError{Args: values, NumRange: &[2]int{1, 2}}
+		}
+		x, err := funcArg[T](values, 0)
+		if err != nil {
+			return nil, err
+		}
+		return funcResult(fn(x)), nil
+	}
+}
+
+// Func2 adapts a function of two arguments to an [ExprFunc], arguments and results are converted like [Func1].
+func Func2[T1, T2, R Scalar](fn func(T1, T2) R) ExprFunc {
+	return func(values []any) (any, error) {
+		if len(values) != 2 {
+			return nil, &FuncError{Args: values, NumRange: &[2]int{2, 3}}
+		}
+		x, err := funcArg[T1](values, 0)
+		if err != nil {
+			return nil, err
+		}
+		y, err := funcArg[T2](values, 1)
+		if err != nil {
+			return nil, err
+		}
+		return funcResult(fn(x, y)), nil
+	}
+}
+
+// FuncVariadic adapts a variadic function to an [ExprFunc], arguments and results are converted like [Func1].
+// It reports an error for calls with fewer than minArgs arguments.
+func FuncVariadic[T, R Scalar](minArgs int, fn func(...T) R) ExprFunc {
+	return func(values []any) (any, error) {
+		if len(values) < minArgs {
+			return nil, &FuncError{Args: values, NumRange: &[2]int{minArgs, -1}}
+		}
+		args := make([]T, len(values))
+		for i := range values {
+			x, err := funcArg[T](values, i)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = x
+		}
+		return funcResult(fn(args...)), nil
+	}
+}
+
+// funcArg converts the i-th value to type T with the rules of assigning fields.
+// Values of other kinds are reported as unsupported types, e.g. strings for numbers,
+// and failed conversions as errors of the argument.
+func funcArg[T Scalar](values []any, i int) (T, error) {
+	var x T
+	v := reflect.ValueOf(&x).Elem()
+	value := engine.ConvertToNumber(values[i])
+
+	kind := fieldKind(v.Type())
+	switch val := value.(type) {
+	case bool:
+		if v.Kind() == reflect.Bool {
+			v.SetBool(val)
+			return x, nil
+		}
+	case string:
+		if kind == stringKind {
+			v.SetString(val)
+			return x, nil
+		}
+	case int64, float64, complex128:
+		if kind == stringKind || v.Kind() == reflect.Bool {
+			break
+		}
+		if err := fieldSetStrict(v, value, kind); err != nil {
+			return x, &FuncError{Args: values, Arg: i + 1, Err: err}
+		}
+		return x, nil
+	}
+	return x, &FuncError{Args: values}
+}
+
+// funcResult converts the result to int64, float64, complex128, string or bool.
+func funcResult[R Scalar](r R) any {
+	v := reflect.ValueOf(r)
+	if v.Kind() == reflect.Bool {
+		return v.Bool()
+	}
+	return fieldNumber(v, fieldKind(v.Type()), false)
+}
@@ -0,0 +1,113 @@
+package fieldenum
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/QAQandOwO/godget/fieldenum/internal/engine"
+)
+
+type funcCelsius float64
+
+func TestFunc(t *testing.T) {
+	funcs := map[string]ExprFunc{
+		"floor":  Func1[float64](math.Floor),
+		"half":   Func1(func(x int) float64 { return float64(x) / 2 }),
+		"shout":  Func1(strings.ToUpper),
+		"not":    Func1(func(x bool) bool { return !x }),
+		"conj":   Func1(func(x complex128) complex128 { return complex(real(x), -imag(x)) }),
+		"narrow": Func1(func(x int8) int8 { return x }),
+		"kelvin": Func1(func(x funcCelsius) funcCelsius { return x + 273.15 }),
+		"atan2":  Func2(math.Atan2),
+		"repeat": Func2(strings.Repeat),
+		"sum": FuncVariadic[int](1, func(xs ...int) int {
+			sum := 0
+			for _, x := range xs {
+				sum += x
+			}
+			return sum
+		}),
+	}
+
+	tests := []struct {
+		expr string
+		want any
+		err  string
+	}{
+		0:  {expr: "floor(2.5)", want: 2.0},
+		1:  {expr: "floor(3)", want: 3.0},
+		2:  {expr: "half(5)", want: 2.5},
+		3:  {expr: "half(4.0)", want: 2.0},
+		4:  {expr: `shout("ok")`, want: "OK"},
+		5:  {expr: "not(1 > 2)", want: true},
+		6:  {expr: "conj(1)", want: complex(1, 0)},
+		7:  {expr: "kelvin(0)", want: 273.15},
+		8:  {expr: "atan2(0, 1)", want: 0.0},
+		9:  {expr: `repeat("ab", 2)`, want: "abab"},
+		10: {expr: "sum(1, 2, 3)", want: int64(6)},
+		11: {expr: "floor()", err: "call function floor on too few arguments"},
+		12: {expr: "floor(1, 2)", err: "call function floor on too many arguments"},
+		13: {expr: "sum()", err: "call function sum on too few arguments"},
+		14: {expr: `floor("x")`, err: "call function floor(string) on unsupported type"},
+		15: {expr: "half(2.5)", err: `call function half on argument 1: value "2.5" truncated to type int`},
+		16: {expr: "narrow(200)", err: `call function narrow on argument 1: value "200" overflows type int8`},
+		17: {expr: "sum(1, 2.5)", err: `call function sum on argument 2: value "2.5" truncated to type int`},
+		18: {expr: "floor(1i)", err: `call function floor on argument 1: value "(0+1i)" truncated to type float64`},
+	}
+	for i, test := range tests {
+		conf := newConfig()
+		if err := conf.AddFuncs(funcs); err != nil {
+			t.Fatal(err)
+		}
+		prog, err := engine.Parse(test.expr)
+		if err != nil {
+			t.Fatalf("[%d]ERROR: %v", i, err)
+		}
+		got, err := prog.Eval(conf.Config)
+		switch {
+		case test.err != "":
+			var funcErr *FuncError
+			if !errors.As(err, &funcErr) || funcErr.Error() != test.err {
+				t.Errorf("[%d]ERROR: got error %v, want %s", i, err, test.err)
+			}
+		case err != nil || got != test.want:
+			t.Errorf("[%d]ERROR: got %v (%T) %v, want %v (%T)", i, got, got, err, test.want, test.want)
+		}
+	}
+
+	conf := newConfig()
+	if err := conf.AddFuncs(funcs); err != nil {
+		t.Fatal(err)
+	}
+	prog, _ := engine.Parse("sum(1, 2.5)")
+	_, err := prog.Eval(conf.Config)
+	var funcErr *FuncError
+	if !errors.As(err, &funcErr) || funcErr.Func != "sum" || funcErr.Arg != 2 || funcErr.Err == nil {
+		t.Errorf("ERROR: got error %#v, want FuncError of sum on argument 2", err)
+	}
+
+	got := New[struct {
+		A float64 `fieldenum:"floor(iota / 2.0 + 10)"`
+		B float64
+		C float64
+	}](WithFuncs(map[string]ExprFunc{"floor": Func1(math.Floor)}))
+	if got.A != 10 || got.B != 10 || got.C != 11 {
+		t.Errorf("ERROR: got %v, want {10 10 11}", got)
+	}
+
+	// registered functions shadow built-in functions added after WithFuncs, also in exact arithmetic
+	shadowed := New[struct {
+		A int `fieldenum:"round(7)"`
+	}](WithFuncs(map[string]ExprFunc{"round": Func1(func(x int) int { return x / 5 * 5 })}), WithExactArithmetic())
+	if shadowed.A != 5 {
+		t.Errorf("ERROR: got %v, want {5}", shadowed)
+	}
+	if _, err := TryNew[struct{ A int }](WithFuncs(map[string]ExprFunc{"sqrt": Func1(math.Sqrt)})); err == nil {
+		t.Errorf("ERROR: got no error, want error for existed function sqrt")
+	}
+	if !ReservedFunc("sqrt") || ReservedFunc("floor") || ReservedFunc("foo") {
+		t.Errorf("ERROR: got reserved %v %v %v, want true false false", ReservedFunc("sqrt"), ReservedFunc("floor"), ReservedFunc("foo"))
+	}
+}
@@ -0,0 +1,133 @@
+// Package engine implements the expression evaluator shared by package fieldenum and package expr.
+package engine
+
+import (
+	"context"
+	"errors"
+)
+
+// Func is a function which can be called in expressions.
+type Func = func(values []any) (any, error)
+
+// Config resolves identifiers and functions during evaluation.
+type Config struct {
+	Funcs  map[string]Func
+	Values map[string]any
+	// Exact enables exact arithmetic, integers are evaluated as *big.Int and
+	// float literals as *big.Rat, see exact.go for details.
+	Exact bool
+	// Lookup resolves identifiers which are neither built-in nor registered values.
+	// It returns a nil value and a nil error for unsupported identifiers.
+	Lookup func(name string) (any, error)
+	// Trace is called with the source and the value of every evaluated sub-expression
+	// except parentheses in evaluation order, so the last call is of the whole expression.
+	Trace func(expr string, value any)
+	// Limits restricts evaluations of expressions from untrusted sources.
+	Limits Limits
+}
+
+// Limits restricts the size and the cost of evaluations, zero values mean no limits.
+type Limits struct {
+	// MaxDepth is the maximum nesting depth of the syntax tree of expressions.
+	MaxDepth int
+	// MaxNodes is the maximum number of nodes of the syntax tree of expressions.
+	MaxNodes int
+	// Funcs holds the names of allowed functions, all functions are allowed if Funcs is nil.
+	// The conditional function if is always allowed.
+	Funcs map[string]bool
+	// Context stops evaluations once it's done.
+	// It's checked before every binary operation and function call,
+	// but running functions are not interrupted.
+	Context context.Context
+}
+
+// checkProgram returns a *LimitError if the program is too large or the context is done.
+func (l *Limits) checkProgram(p *Program) error {
+	switch {
+	case l.MaxDepth > 0 && p.depth > l.MaxDepth:
+		return newLimitError(LimitDepth).setCount(p.depth, l.MaxDepth)
+	case l.MaxNodes > 0 && p.nodes > l.MaxNodes:
+		return newLimitError(LimitNodes).setCount(p.nodes, l.MaxNodes)
+	}
+	return l.checkContext()
+}
+
+// checkContext returns a *LimitError if the context is done.
+func (l *Limits) checkContext() error {
+	if l.Context == nil {
+		return nil
+	}
+	select {
+	case <-l.Context.Done():
+		return newLimitError(LimitContext).setErr(l.Context.Err())
+	default:
+		return nil
+	}
+}
+
+// checkFunc returns a *LimitError if the function isn't allowed.
+func (l *Limits) checkFunc(name string) error {
+	if l.Funcs == nil || l.Funcs[name] {
+		return nil
+	}
+	return newLimitError(LimitFunc).setFunc(name)
+}
+
+// NewConfig returns a Config without registered functions and values.
+func NewConfig() *Config { return &Config{Funcs: make(map[string]Func), Values: make(map[string]any)} }
+
+// AddFuncs registers functions, the function names must be unique.
+// Built-in functions can't be replaced, except those in shadowedFuncs.
+func (conf *Config) AddFuncs(funcs map[string]Func) error {
+	for name, fn := range funcs {
+		if _, ok := conf.Funcs[name]; ok || ReservedFunc(name) {
+			return errors.New(`existed function with name "` + name + `"`)
+		}
+		conf.Funcs[name] = fn
+	}
+	return nil
+}
+
+// AddValues registers values, the value names must be unique.
+// The name iota is reserved for the index of fields, which is set in Values directly.
+func (conf *Config) AddValues(values map[string]any) error {
+	for name, value := range values {
+		if _, ok := conf.Value(name); ok || name == "iota" {
+			return errors.New(`existed value with name "` + name + `"`)
+		}
+		conf.Values[name] = value
+	}
+	return nil
+}
+
+// ReservedFunc reports whether name is a built-in function which registered functions can't replace.
+func ReservedFunc(name string) bool {
+	_, builtin := builtinFuncs[name]
+	return builtin && !shadowedFuncs[name]
+}
+
+// Func returns the registered or built-in function named name.
+// Registered functions shadow built-in functions in shadowedFuncs.
+func (conf *Config) Func(name string) (Func, bool) {
+	fn, ok := conf.Funcs[name]
+	if ok {
+		return fn, true
+	}
+	fn, ok = builtinFuncs[name]
+	return fn, ok
+}
+
+// Value returns the built-in or registered value named name.
+// iota is found only if it's set, otherwise it's an unsupported identifier.
+func (conf *Config) Value(name string) (any, bool) {
+	if name == "iota" {
+		value, ok := conf.Values["iota"]
+		return value, ok
+	}
+	value, ok := builtinValues[name]
+	if ok {
+		return value, true
+	}
+	value, ok = conf.Values[name]
+	return value, ok
+}
@@ -0,0 +1,189 @@
+package engine
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+// OpError is an error of an operator applied on unsupported operands.
+type OpError struct {
+	Op     string
+	X      any
+	Y      any
+	ArgNum int
+	ValIdx int
+}
+
+func newOpError(op string) *OpError            { return &OpError{Op: op} }
+func (e *OpError) setUnsupported() *OpError    { e.ArgNum, e.ValIdx = 0, 0; return e }
+func (e *OpError) setUnaryType(x any) *OpError { e.X, e.ArgNum, e.ValIdx = x, 1, 0; return e }
+func (e *OpError) setBinaryType(x, y any) *OpError {
+	e.X, e.Y, e.ArgNum, e.ValIdx = x, y, 2, 0
+	return e
+}
+func (e *OpError) setUnaryVal(x any) *OpError { e.X, e.ArgNum, e.ValIdx = x, 1, 1; return e }
+func (e *OpError) setBinaryVal(x, y any, second bool) *OpError {
+	e.X, e.Y, e.ArgNum = x, y, 2
+	if !second {
+		e.ValIdx = 1
+	} else {
+		e.ValIdx = 2
+	}
+	return e
+}
+func (e *OpError) Error() string {
+	switch e.ValIdx {
+	case 0:
+		switch e.ArgNum {
+		case 1:
+			return fmt.Sprintf(`use operator "%s" on unsupported type for %s(%T)`, e.Op, e.Op, e.X)
+		case 2:
+			return fmt.Sprintf(`use operator "%s" on unsupported type for (%T)%s(%T)`, e.Op, e.X, e.Op, e.Y)
+		}
+	case 1:
+		switch e.ArgNum {
+		case 1:
+			return fmt.Sprintf(`use operator "%s" on unsupported value for %s(%v)`, e.Op, e.Op, e.X)
+		case 2:
+			return fmt.Sprintf(`use operator "%s" on unsupported first value for (%v)%s(%v)`, e.Op, e.X, e.Op, e.Y)
+		}
+	case 2:
+		return fmt.Sprintf(`use operator "%s" on unsupported second value for (%v)%s(%v)`, e.Op, e.X, e.Op, e.Y)
+	}
+	return `unsupported operator "` + e.Op + `"`
+}
+
+// FuncError is an error of calling a function, which doesn't exist or is called on unsupported arguments.
+// Arg is the position of the argument counting from 1 and Err is the cause,
+// if an argument can't be converted to the parameter type of an adapted function.
+type FuncError struct {
+	Func     string
+	Args     []any
+	NumRange *[2]int
+	Arg      int
+	Err      error
+}
+
+func newFuncError(fn string) *FuncError                          { return &FuncError{Func: fn} }
+func (e *FuncError) setNotExisted() *FuncError                   { e.Args = nil; return e }
+func (e *FuncError) setUnsupportedArgType(args []any) *FuncError { e.Args = args; return e }
+func (e *FuncError) setNum(args []any, n int) *FuncError         { return e.setNumRange(args, n, n+1) }
+func (e *FuncError) setNumAtLeast(args []any, n int) *FuncError  { return e.setNumRange(args, n, -1) }
+func (e *FuncError) setNumAtMost(args []any, n int) *FuncError   { return e.setNumRange(args, -1, n+1) }
+func (e *FuncError) Unwrap() error                               { return e.Err }
+func (e *FuncError) setNumRange(args []any, start, end int) *FuncError {
+	e.Args, e.NumRange = args, &[2]int{start, end}
+	return e
+}
+func (e *FuncError) Error() string {
+	switch {
+	case e.Args == nil:
+		return `call non-existed function "` + e.Func + `"`
+	case e.Err != nil:
+		return fmt.Sprintf("call function %s on argument %d: %v", e.Func, e.Arg, e.Err)
+	case e.NumRange == nil:
+		var builder strings.Builder
+		builder.WriteString("call function " + e.Func + "(")
+		for i, arg := range e.Args {
+			if i > 0 {
+				builder.WriteString(" ")
+			}
+			builder.WriteString(fmt.Sprintf("%T", arg))
+		}
+		builder.WriteString(") on unsupported type")
+		return builder.String()
+	case e.NumRange[0] >= 0 && len(e.Args) < e.NumRange[0]:
+		return "call function " + e.Func + " on too few arguments"
+	case e.NumRange[1] >= 0 && len(e.Args) >= e.NumRange[1]:
+		return "call function " + e.Func + " on too many arguments"
+	default:
+		return ""
+	}
+}
+
+// Limits of evaluations reported by LimitError.
+const (
+	LimitDepth   = "depth"
+	LimitNodes   = "nodes"
+	LimitFunc    = "func"
+	LimitContext = "context"
+)
+
+// LimitError is an error of an evaluation exceeding Limits.
+// Limit is one of LimitDepth, LimitNodes, LimitFunc and LimitContext.
+// Value and Max are the actual and allowed size of the expression for LimitDepth and LimitNodes,
+// Func is the disallowed function for LimitFunc, and Err is the error of the context for LimitContext.
+type LimitError struct {
+	Limit string
+	Value int
+	Max   int
+	Func  string
+	Err   error
+}
+
+func newLimitError(limit string) *LimitError                { return &LimitError{Limit: limit} }
+func (e *LimitError) setCount(value, limit int) *LimitError { e.Value, e.Max = value, limit; return e }
+func (e *LimitError) setFunc(fn string) *LimitError         { e.Func = fn; return e }
+func (e *LimitError) setErr(err error) *LimitError          { e.Err = err; return e }
+func (e *LimitError) Unwrap() error                         { return e.Err }
+func (e *LimitError) Error() string {
+	switch e.Limit {
+	case LimitDepth:
+		return fmt.Sprintf("expression depth %d exceeds limit %d", e.Value, e.Max)
+	case LimitNodes:
+		return fmt.Sprintf("expression of %d nodes exceeds limit %d", e.Value, e.Max)
+	case LimitFunc:
+		return `call disallowed function "` + e.Func + `"`
+	case LimitContext:
+		return "evaluation stopped: " + e.Err.Error()
+	}
+	return "exceed limit " + e.Limit
+}
+
+// ExprError is an error of an expression, which locates the invalid part of the expression.
+// The invalid part is located by positions in a file set private to the expression, see [ExprError.Columns].
+type ExprError struct {
+	Err   error
+	Expr  string
+	fset  *token.FileSet
+	start token.Pos
+	end   token.Pos
+}
+
+func newExprError() *ExprError                              { return &ExprError{start: -1} }
+func (e *ExprError) setFset(fset *token.FileSet) *ExprError { e.fset = fset; return e }
+func (e *ExprError) setErr(err error) *ExprError            { e.Err = err; return e }
+func (e *ExprError) setExpr(expr string) *ExprError         { e.Expr = expr; return e }
+func (e *ExprError) setPos(start, end token.Pos) *ExprError { e.start, e.end = start, end; return e }
+func (e *ExprError) Unwrap() error                          { return e.Err }
+
+// Columns returns the 1-based column range [start, end) of the invalid part of the expression.
+// The whole expression is returned if the invalid part is unknown.
+func (e *ExprError) Columns() (start, end int) {
+	start, end = e.offsets()
+	return start + 1, end + 1
+}
+
+func (e *ExprError) offsets() (start, end int) {
+	start, end = 0, len(e.Expr)
+	if e.fset != nil {
+		start = e.fset.Position(e.start).Offset
+		if start < 0 {
+			start = 0
+		}
+		end = e.fset.Position(e.end).Offset
+		if l := len(e.Expr); end > l || end <= 0 {
+			end = l
+		}
+	}
+	return start, end
+}
+
+func (e *ExprError) Error() string {
+	start, end := e.offsets()
+	if e.Err == nil {
+		return `invalid expression "` + e.Expr[start:end] + `"`
+	}
+	return e.Err.Error() + ` with expression "` + e.Expr[start:end] + `"`
+}
@@ -0,0 +1,437 @@
+package engine
+
+import (
+	"go/token"
+	"math"
+	"math/big"
+)
+
+// maxExactBits limits the bit length of results of left shifts and powers in exact arithmetic,
+// so that a small expression can't exhaust memory.
+const maxExactBits = 1 << 16
+
+type (
+	// exactUnaryFunc and exactBinaryFunc evaluate operators in exact arithmetic,
+	// ok is false if any operand has no exact value, then the inexact operator is used.
+	exactUnaryFunc  = func(vx any) (v any, ok bool, err error)
+	exactBinaryFunc = func(vx, vy any) (v any, ok bool, err error)
+)
+
+var (
+	exactUnaryOps = map[token.Token]exactUnaryFunc{
+		token.ADD: exactPos,
+		token.SUB: exactNeg,
+		token.XOR: exactNot,
+	}
+
+	exactBinaryOps = map[token.Token]exactBinaryFunc{
+		token.ADD:     exactArith((*big.Int).Add, (*big.Rat).Add),
+		token.SUB:     exactArith((*big.Int).Sub, (*big.Rat).Sub),
+		token.MUL:     exactArith((*big.Int).Mul, (*big.Rat).Mul),
+		token.QUO:     exactQuo,
+		token.REM:     exactIntOp("%", (*big.Int).Rem),
+		token.AND:     exactIntOp("&", (*big.Int).And),
+		token.OR:      exactIntOp("|", (*big.Int).Or),
+		token.XOR:     exactIntOp("^", (*big.Int).Xor),
+		token.AND_NOT: exactIntOp("&^", (*big.Int).AndNot),
+		token.SHL:     exactShift("<<"),
+		token.SHR:     exactShift(">>"),
+		token.EQL:     exactCompare(token.EQL),
+		token.NEQ:     exactCompare(token.NEQ),
+		token.LSS:     exactCompare(token.LSS),
+		token.LEQ:     exactCompare(token.LEQ),
+		token.GTR:     exactCompare(token.GTR),
+		token.GEQ:     exactCompare(token.GEQ),
+	}
+
+	exactFuncs = map[string]func(values []any) (any, bool, error){
+		"int":   exactToInt,
+		"float": exactToFloat,
+		"abs":   exactAbs,
+		"max":   exactExtremum(1),
+		"min":   exactExtremum(-1),
+		"pow":   exactPow,
+		"floor": exactRound("floor", func(num, denom *big.Int) *big.Int {
+			return new(big.Int).Div(num, denom)
+		}),
+		"ceil": exactRound("ceil", func(num, denom *big.Int) *big.Int {
+			return new(big.Int).Neg(new(big.Int).Div(new(big.Int).Neg(num), denom))
+		}),
+		"trunc": exactRound("trunc", func(num, denom *big.Int) *big.Int {
+			return new(big.Int).Quo(num, denom)
+		}),
+		"round": exactRound("round", roundHalfAway),
+		"gcd":   exactGCD,
+		"lcm":   exactLCM,
+		"bit":   exactBit,
+		"fact":  exactFact,
+	}
+)
+
+// ExactNumber converts integers to *big.Int, finite floats to *big.Rat,
+// and returns *big.Int and *big.Rat values unchanged.
+// It reports false for values of other types, NaN and infinities.
+func ExactNumber(vx any) (any, bool) {
+	switch x := vx.(type) {
+	case *big.Int, *big.Rat:
+		return x, true
+	case uint:
+		return new(big.Int).SetUint64(uint64(x)), true
+	case uint64:
+		return new(big.Int).SetUint64(x), true
+	case uintptr:
+		return new(big.Int).SetUint64(uint64(x)), true
+	}
+
+	switch x := ConvertToNumber(vx).(type) {
+	case int64:
+		return big.NewInt(x), true
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return nil, false
+		}
+		return new(big.Rat).SetFloat64(x), true
+	}
+	return nil, false
+}
+
+// inexactNumber converts *big.Int to int64 if it fits, otherwise to float64,
+// and converts *big.Rat to float64. Values of other types are returned unchanged.
+func inexactNumber(vx any) any {
+	switch x := vx.(type) {
+	case *big.Int:
+		if x.IsInt64() {
+			return x.Int64()
+		}
+		f, _ := new(big.Float).SetInt(x).Float64()
+		return f
+	case *big.Rat:
+		f, _ := x.Float64()
+		return f
+	}
+	return vx
+}
+
+func inexactNumbers(values []any) []any {
+	inexact := make([]any, len(values))
+	for i, v := range values {
+		inexact[i] = inexactNumber(v)
+	}
+	return inexact
+}
+
+// exactRat converts an exact number to *big.Rat.
+func exactRat(x any) *big.Rat {
+	if i, ok := x.(*big.Int); ok {
+		return new(big.Rat).SetInt(i)
+	}
+	return x.(*big.Rat)
+}
+
+// exactInt converts vx to *big.Int, it reports false if vx is not an exact integer.
+func exactInt(vx any) (*big.Int, bool) {
+	switch x, _ := ExactNumber(vx); x := x.(type) {
+	case *big.Int:
+		return x, true
+	case *big.Rat:
+		if x.IsInt() {
+			return x.Num(), true
+		}
+	}
+	return nil, false
+}
+
+func exactPos(vx any) (any, bool, error) {
+	x, ok := ExactNumber(vx)
+	return x, ok, nil
+}
+
+func exactNeg(vx any) (any, bool, error) {
+	switch x, _ := ExactNumber(vx); x := x.(type) {
+	case *big.Int:
+		return new(big.Int).Neg(x), true, nil
+	case *big.Rat:
+		return new(big.Rat).Neg(x), true, nil
+	}
+	return nil, false, nil
+}
+
+func exactNot(vx any) (any, bool, error) {
+	x, ok := exactInt(vx)
+	if !ok {
+		return nil, false, nil
+	}
+	return new(big.Int).Not(x), true, nil
+}
+
+// exactArith returns an arithmetic operator, which evaluates on *big.Int if both operands are integers,
+// otherwise on *big.Rat.
+func exactArith(intOp func(z, x, y *big.Int) *big.Int, ratOp func(z, x, y *big.Rat) *big.Rat) exactBinaryFunc {
+	return func(vx, vy any) (any, bool, error) {
+		x, okX := ExactNumber(vx)
+		y, okY := ExactNumber(vy)
+		if !okX || !okY {
+			return nil, false, nil
+		}
+		ix, isIntX := x.(*big.Int)
+		iy, isIntY := y.(*big.Int)
+		if isIntX && isIntY {
+			return intOp(new(big.Int), ix, iy), true, nil
+		}
+		return ratOp(new(big.Rat), exactRat(x), exactRat(y)), true, nil
+	}
+}
+
+// exactQuo truncates the quotient of integers like Go integer division.
+func exactQuo(vx, vy any) (any, bool, error) {
+	x, okX := ExactNumber(vx)
+	y, okY := ExactNumber(vy)
+	if !okX || !okY {
+		return nil, false, nil
+	}
+	if exactRat(y).Sign() == 0 {
+		return nil, true, newOpError("/").setBinaryVal(vx, vy, true)
+	}
+	ix, isIntX := x.(*big.Int)
+	iy, isIntY := y.(*big.Int)
+	if isIntX && isIntY {
+		return new(big.Int).Quo(ix, iy), true, nil
+	}
+	return new(big.Rat).Quo(exactRat(x), exactRat(y)), true, nil
+}
+
+// exactIntOp returns an operator on integers, division by zero is reported for "%".
+func exactIntOp(op string, intOp func(z, x, y *big.Int) *big.Int) exactBinaryFunc {
+	return func(vx, vy any) (any, bool, error) {
+		x, okX := exactInt(vx)
+		y, okY := exactInt(vy)
+		if !okX || !okY {
+			return nil, false, nil
+		}
+		if op == "%" && y.Sign() == 0 {
+			return nil, true, newOpError(op).setBinaryVal(vx, vy, true)
+		}
+		return intOp(new(big.Int), x, y), true, nil
+	}
+}
+
+func exactShift(op string) exactBinaryFunc {
+	return func(vx, vy any) (any, bool, error) {
+		x, okX := exactInt(vx)
+		y, okY := exactInt(vy)
+		if !okX || !okY {
+			return nil, false, nil
+		}
+		if y.Sign() < 0 {
+			return nil, true, newOpError(op).setBinaryVal(vx, vy, true)
+		}
+		if op == ">>" {
+			// shifting out every bit leaves the sign, so right shifts have no limit
+			if !y.IsInt64() || y.Int64() > int64(x.BitLen()) {
+				if x.Sign() < 0 {
+					return big.NewInt(-1), true, nil
+				}
+				return new(big.Int), true, nil
+			}
+			return new(big.Int).Rsh(x, uint(y.Int64())), true, nil
+		}
+		if !y.IsInt64() || y.Int64() > maxExactBits || int64(x.BitLen())+y.Int64() > maxExactBits {
+			return nil, true, newOpError(op).setBinaryVal(vx, vy, true)
+		}
+		return new(big.Int).Lsh(x, uint(y.Int64())), true, nil
+	}
+}
+
+func exactCompare(tok token.Token) exactBinaryFunc {
+	return func(vx, vy any) (any, bool, error) {
+		x, okX := ExactNumber(vx)
+		y, okY := ExactNumber(vy)
+		if !okX || !okY {
+			return nil, false, nil
+		}
+		return compareOrdered(tok, int64(exactRat(x).Cmp(exactRat(y))), 0), true, nil
+	}
+}
+
+func exactToInt(values []any) (any, bool, error) {
+	if err := argNumEq("int", values, 1); err != nil {
+		return nil, true, err
+	}
+	switch x, _ := ExactNumber(values[0]); x := x.(type) {
+	case *big.Int:
+		return x, true, nil
+	case *big.Rat:
+		return new(big.Int).Quo(x.Num(), x.Denom()), true, nil
+	}
+	return nil, false, nil
+}
+
+func exactToFloat(values []any) (any, bool, error) {
+	if err := argNumEq("float", values, 1); err != nil {
+		return nil, true, err
+	}
+	x, ok := ExactNumber(values[0])
+	if !ok {
+		return nil, false, nil
+	}
+	return exactRat(x), true, nil
+}
+
+func exactAbs(values []any) (any, bool, error) {
+	if err := argNumEq("abs", values, 1); err != nil {
+		return nil, true, err
+	}
+	switch x, _ := ExactNumber(values[0]); x := x.(type) {
+	case *big.Int:
+		return new(big.Int).Abs(x), true, nil
+	case *big.Rat:
+		return new(big.Rat).Abs(x), true, nil
+	}
+	return nil, false, nil
+}
+
+// exactExtremum returns max if sign is 1, or min if sign is -1.
+func exactExtremum(sign int) func(values []any) (any, bool, error) {
+	return func(values []any) (any, bool, error) {
+		var extremum any
+		for _, v := range values {
+			x, ok := ExactNumber(v)
+			if !ok {
+				return nil, false, nil
+			}
+			if extremum == nil || exactRat(x).Cmp(exactRat(extremum)) == sign {
+				extremum = x
+			}
+		}
+		return extremum, extremum != nil, nil
+	}
+}
+
+// exactPow evaluates powers with integer exponents,
+// results exceeding maxExactBits are evaluated inexactly.
+func exactPow(values []any) (any, bool, error) {
+	if err := argNumEq("pow", values, 2); err != nil {
+		return nil, true, err
+	}
+	x, okX := ExactNumber(values[0])
+	y, okY := exactInt(values[1])
+	if !okX || !okY || !y.IsInt64() {
+		return nil, false, nil
+	}
+
+	base, exp := exactRat(x), y.Int64()
+	bitLen := int64(base.Num().BitLen() + base.Denom().BitLen())
+	if exp < 0 {
+		if base.Sign() == 0 {
+			return nil, false, nil
+		}
+		base, exp = new(big.Rat).Inv(base), -exp
+	}
+	if exp > maxExactBits || bitLen*exp > maxExactBits {
+		return nil, false, nil
+	}
+
+	e := big.NewInt(exp)
+	num := new(big.Int).Exp(base.Num(), e, nil)
+	denom := new(big.Int).Exp(base.Denom(), e, nil)
+	if _, isInt := x.(*big.Int); isInt && y.Sign() >= 0 {
+		return num, true, nil
+	}
+	return new(big.Rat).SetFrac(num, denom), true, nil
+}
+
+// exactRound returns a rounding function on *big.Rat, the denominator is positive.
+func exactRound(name string, round func(num, denom *big.Int) *big.Int) func(values []any) (any, bool, error) {
+	return func(values []any) (any, bool, error) {
+		if err := argNumEq(name, values, 1); err != nil {
+			return nil, true, err
+		}
+		switch x, _ := ExactNumber(values[0]); x := x.(type) {
+		case *big.Int:
+			return x, true, nil
+		case *big.Rat:
+			return round(x.Num(), x.Denom()), true, nil
+		}
+		return nil, false, nil
+	}
+}
+
+// roundHalfAway rounds half away from zero like math.Round.
+func roundHalfAway(num, denom *big.Int) *big.Int {
+	// round(x) = sign(x) * floor(|x| + 1/2) = sign(x) * floor((2|num| + denom) / (2 denom))
+	twice := new(big.Int).Lsh(new(big.Int).Abs(num), 1)
+	q := new(big.Int).Div(twice.Add(twice, denom), new(big.Int).Lsh(denom, 1))
+	if num.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q
+}
+
+// exactInts converts all values to *big.Int, it reports false if any value isn't an exact integer.
+func exactInts(values []any) ([]*big.Int, bool) {
+	ints := make([]*big.Int, len(values))
+	for i, v := range values {
+		x, ok := exactInt(v)
+		if !ok {
+			return nil, false
+		}
+		ints[i] = x
+	}
+	return ints, true
+}
+
+func exactGCD(values []any) (any, bool, error) {
+	if err := argNumGe("gcd", values, 2); err != nil {
+		return nil, true, err
+	}
+	ints, ok := exactInts(values)
+	if !ok {
+		return nil, false, nil
+	}
+	d := new(big.Int)
+	for _, x := range ints {
+		d.GCD(nil, nil, d, new(big.Int).Abs(x))
+	}
+	return d, true, nil
+}
+
+func exactLCM(values []any) (any, bool, error) {
+	if err := argNumGe("lcm", values, 2); err != nil {
+		return nil, true, err
+	}
+	ints, ok := exactInts(values)
+	if !ok {
+		return nil, false, nil
+	}
+	m := lcmBig(ints)
+	if m.BitLen() > maxExactBits {
+		return nil, false, nil
+	}
+	return m, true, nil
+}
+
+// exactBit evaluates 1<<n, results exceeding maxExactBits are evaluated inexactly.
+func exactBit(values []any) (any, bool, error) {
+	if err := argNumEq("bit", values, 1); err != nil {
+		return nil, true, err
+	}
+	n, ok := exactInt(values[0])
+	if !ok || n.Sign() < 0 || !n.IsInt64() || n.Int64() >= maxExactBits {
+		return nil, false, nil
+	}
+	return new(big.Int).Lsh(big.NewInt(1), uint(n.Int64())), true, nil
+}
+
+// maxExactFact limits the argument of fact in exact arithmetic, 5000! has about 54000 bits.
+const maxExactFact = 5000
+
+func exactFact(values []any) (any, bool, error) {
+	if err := argNumEq("fact", values, 1); err != nil {
+		return nil, true, err
+	}
+	n, ok := exactInt(values[0])
+	if !ok || n.Sign() < 0 || !n.IsInt64() || n.Int64() > maxExactFact {
+		return nil, false, nil
+	}
+	return new(big.Int).MulRange(1, n.Int64()), true, nil
+}
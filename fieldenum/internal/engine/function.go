@@ -1,38 +1,71 @@
-package fieldenum
+package engine
 
 import (
+	"fmt"
 	"math"
+	"math/big"
+	"math/bits"
 	"math/cmplx"
 )
 
-type ExprFunc = func(values []any) (any, error)
-
-var builtinFuncs = map[string]ExprFunc{
-	"int":     toInt,
-	"float":   toFloat,
-	"complex": toComplex,
-	"real":    realPart,
-	"imag":    imagPart,
-	"max":     maxNum,
-	"min":     minNum,
-	"abs":     abs,
-	"sqrt":    sqrt,
-	"pow":     pow,
-	"exp":     exp,
-	"log":     log,
-	"log10":   log10,
-	"sin":     sin,
-	"cos":     cos,
-	"tan":     tan,
-	"asin":    asin,
-	"acos":    acos,
-	"atan":    atan,
-	"sinh":    sinh,
-	"cosh":    cosh,
-	"tanh":    tanh,
-	"asinh":   asinh,
-	"acosh":   acosh,
-	"atanh":   atanh,
+var builtinFuncs = map[string]Func{
+	"int":      toInt,
+	"float":    toFloat,
+	"complex":  toComplex,
+	"real":     realPart,
+	"imag":     imagPart,
+	"max":      maxNum,
+	"min":      minNum,
+	"abs":      abs,
+	"sqrt":     sqrt,
+	"pow":      pow,
+	"exp":      exp,
+	"log":      log,
+	"log10":    log10,
+	"sin":      sin,
+	"cos":      cos,
+	"tan":      tan,
+	"asin":     asin,
+	"acos":     acos,
+	"atan":     atan,
+	"sinh":     sinh,
+	"cosh":     cosh,
+	"tanh":     tanh,
+	"asinh":    asinh,
+	"acosh":    acosh,
+	"atanh":    atanh,
+	"log2":     log2,
+	"hypot":    hypot,
+	"atan2":    atan2,
+	"floor":    roundFunc("floor", math.Floor),
+	"ceil":     roundFunc("ceil", math.Ceil),
+	"round":    roundFunc("round", math.Round),
+	"trunc":    roundFunc("trunc", math.Trunc),
+	"sign":     sign,
+	"clamp":    clamp,
+	"gcd":      gcd,
+	"lcm":      lcm,
+	"fact":     fact,
+	"bit":      bit,
+	"popcount": bitCount("popcount", func(x uint64) int { return bits.OnesCount64(x) }),
+	"clz":      bitCount("clz", bits.LeadingZeros64),
+	"ctz":      bitCount("ctz", bits.TrailingZeros64),
+	"lower":    lower,
+	"upper":    upper,
+	"snake":    snake,
+	"kebab":    kebab,
+	"fmt":      format,
+}
+
+// shadowedFuncs are built-in functions which registered functions of the same names replace.
+// They were added after custom functions could be registered, so that registrations of their names keep working,
+// while the names of the other built-in functions can't be registered.
+var shadowedFuncs = map[string]bool{
+	"log2": true, "hypot": true, "atan2": true,
+	"floor": true, "ceil": true, "round": true, "trunc": true,
+	"sign": true, "clamp": true, "gcd": true, "lcm": true, "fact": true,
+	"bit": true, "popcount": true, "clz": true, "ctz": true,
+	"lower": true, "upper": true, "snake": true, "kebab": true, "fmt": true,
 }
 
 func argNumEq(fn string, values []any, num int) error {
@@ -56,6 +89,11 @@ func argNumGe(fn string, values []any, atLeast int) error {
 	return nil
 }
 
+// invalidArg returns the error of an argument which is out of the domain of the function.
+func invalidArg(fn string, arg any) error {
+	return fmt.Errorf(`call function %s on invalid argument "%v"`, fn, arg)
+}
+
 func argNumInRange(fn string, values []any, start, end int) error {
 	if len(values) < start || len(values) >= end {
 		return newFuncError(fn).setNumRange(values, start, end)
@@ -69,7 +107,7 @@ func toInt(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch v := convertToNumber(values[0]).(type) {
+	switch v := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return v, nil
 	case float64:
@@ -79,6 +117,11 @@ func toInt(values []any) (any, error) {
 		return int64(v), nil
 	case complex128:
 		return int64(real(v)), nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
 	default:
 		return nil, newFuncError(name).setUnsupportedArgType(values)
 	}
@@ -90,13 +133,18 @@ func toFloat(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch v := convertToNumber(values[0]).(type) {
+	switch v := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return float64(v), nil
 	case float64:
 		return v, nil
 	case complex128:
 		return real(v), nil
+	case bool:
+		if v {
+			return 1.0, nil
+		}
+		return 0.0, nil
 	default:
 		return nil, newFuncError(name).setUnsupportedArgType(values)
 	}
@@ -109,7 +157,7 @@ func toComplex(values []any) (any, error) {
 	}
 
 	if len(values) == 1 {
-		switch x := convertToNumber(values[0]).(type) {
+		switch x := ConvertToNumber(values[0]).(type) {
 		case int64:
 			return complex(float64(x), 0), nil
 		case float64:
@@ -118,7 +166,7 @@ func toComplex(values []any) (any, error) {
 			return x, nil
 		}
 	} else {
-		vx, vy := convertToNumber(values[0]), convertToNumber(values[1])
+		vx, vy := ConvertToNumber(values[0]), ConvertToNumber(values[1])
 		switch x := vx.(type) {
 		case int64:
 			switch y := vy.(type) {
@@ -145,7 +193,7 @@ func realPart(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch v := convertToNumber(values[0]).(type) {
+	switch v := ConvertToNumber(values[0]).(type) {
 	case int64, float64:
 		return v, nil
 	case complex128:
@@ -161,7 +209,7 @@ func imagPart(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch v := convertToNumber(values[0]).(type) {
+	switch v := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return int64(0), nil
 	case float64:
@@ -180,7 +228,7 @@ func maxNum(values []any) (any, error) {
 	}
 
 	var vmax any
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64, float64:
 		vmax = x
 	default:
@@ -190,7 +238,7 @@ func maxNum(values []any) (any, error) {
 	var i int
 loop:
 	for i = 1; i < len(values); i++ {
-		vx := convertToNumber(values[i])
+		vx := ConvertToNumber(values[i])
 		switch m := vmax.(type) {
 		case int64:
 			switch x := vx.(type) {
@@ -245,7 +293,7 @@ func minNum(values []any) (any, error) {
 	}
 
 	var vmin any
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64, float64:
 		vmin = x
 	default:
@@ -255,7 +303,7 @@ func minNum(values []any) (any, error) {
 	var i int
 loop:
 	for i = 1; i < len(values); i++ {
-		vx := convertToNumber(values[i])
+		vx := ConvertToNumber(values[i])
 		switch m := vmin.(type) {
 		case int64:
 			switch x := vx.(type) {
@@ -309,7 +357,7 @@ func abs(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch v := convertToNumber(values[0]).(type) {
+	switch v := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return absInt64(v), nil
 	case float64:
@@ -327,7 +375,7 @@ func sqrt(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch v := convertToNumber(values[0]).(type) {
+	switch v := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Sqrt(float64(v)), nil
 	case float64:
@@ -345,7 +393,7 @@ func pow(values []any) (any, error) {
 		return nil, err
 	}
 
-	vx, vy := convertToNumber(values[0]), convertToNumber(values[1])
+	vx, vy := ConvertToNumber(values[0]), ConvertToNumber(values[1])
 	switch x := vx.(type) {
 	case int64:
 		switch y := vy.(type) {
@@ -384,7 +432,7 @@ func exp(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Exp(float64(x)), nil
 	case float64:
@@ -401,7 +449,7 @@ func log(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Log(float64(x)), nil
 	case float64:
@@ -418,7 +466,7 @@ func log10(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Log10(float64(x)), nil
 	case float64:
@@ -435,7 +483,7 @@ func sin(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Sin(float64(x)), nil
 	case float64:
@@ -452,7 +500,7 @@ func cos(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Cos(float64(x)), nil
 	case float64:
@@ -469,7 +517,7 @@ func tan(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Tan(float64(x)), nil
 	case float64:
@@ -486,7 +534,7 @@ func asin(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Asin(float64(x)), nil
 	case float64:
@@ -503,7 +551,7 @@ func acos(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Acos(float64(x)), nil
 	case float64:
@@ -520,7 +568,7 @@ func atan(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Atan(float64(x)), nil
 	case float64:
@@ -537,7 +585,7 @@ func sinh(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Sinh(float64(x)), nil
 	case float64:
@@ -554,7 +602,7 @@ func cosh(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Cosh(float64(x)), nil
 	case float64:
@@ -571,7 +619,7 @@ func tanh(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Tanh(float64(x)), nil
 	case float64:
@@ -588,7 +636,7 @@ func asinh(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Asinh(float64(x)), nil
 	case float64:
@@ -605,7 +653,7 @@ func acosh(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Acosh(float64(x)), nil
 	case float64:
@@ -622,7 +670,7 @@ func atanh(values []any) (any, error) {
 		return nil, err
 	}
 
-	switch x := convertToNumber(values[0]).(type) {
+	switch x := ConvertToNumber(values[0]).(type) {
 	case int64:
 		return math.Atanh(float64(x)), nil
 	case float64:
@@ -632,3 +680,297 @@ func atanh(values []any) (any, error) {
 	}
 	return nil, newFuncError(name).setUnsupportedArgType(values)
 }
+
+func log2(values []any) (any, error) {
+	name := "log2"
+	if err := argNumEq(name, values, 1); err != nil {
+		return nil, err
+	}
+
+	switch x := ConvertToNumber(values[0]).(type) {
+	case int64:
+		return math.Log2(float64(x)), nil
+	case float64:
+		return math.Log2(x), nil
+	case complex128:
+		return cmplx.Log(x) / math.Ln2, nil
+	}
+	return nil, newFuncError(name).setUnsupportedArgType(values)
+}
+
+// floatArgs converts arguments of int64 and float64 to float64.
+func floatArgs(values []any) ([]float64, bool) {
+	args := make([]float64, len(values))
+	for i, v := range values {
+		switch x := ConvertToNumber(v).(type) {
+		case int64:
+			args[i] = float64(x)
+		case float64:
+			args[i] = x
+		default:
+			return nil, false
+		}
+	}
+	return args, true
+}
+
+func hypot(values []any) (any, error) {
+	name := "hypot"
+	if err := argNumEq(name, values, 2); err != nil {
+		return nil, err
+	}
+
+	args, ok := floatArgs(values)
+	if !ok {
+		return nil, newFuncError(name).setUnsupportedArgType(values)
+	}
+	return math.Hypot(args[0], args[1]), nil
+}
+
+func atan2(values []any) (any, error) {
+	name := "atan2"
+	if err := argNumEq(name, values, 2); err != nil {
+		return nil, err
+	}
+
+	args, ok := floatArgs(values)
+	if !ok {
+		return nil, newFuncError(name).setUnsupportedArgType(values)
+	}
+	return math.Atan2(args[0], args[1]), nil
+}
+
+// roundFunc returns a rounding function, integers are returned unchanged,
+// and complex numbers are rounded by their real and imaginary parts.
+func roundFunc(name string, round func(float64) float64) Func {
+	return func(values []any) (any, error) {
+		if err := argNumEq(name, values, 1); err != nil {
+			return nil, err
+		}
+
+		switch x := ConvertToNumber(values[0]).(type) {
+		case int64:
+			return x, nil
+		case float64:
+			return round(x), nil
+		case complex128:
+			return complex(round(real(x)), round(imag(x))), nil
+		}
+		return nil, newFuncError(name).setUnsupportedArgType(values)
+	}
+}
+
+func sign(values []any) (any, error) {
+	name := "sign"
+	if err := argNumEq(name, values, 1); err != nil {
+		return nil, err
+	}
+
+	switch x := ConvertToNumber(values[0]).(type) {
+	case int64:
+		switch {
+		case x > 0:
+			return int64(1), nil
+		case x < 0:
+			return int64(-1), nil
+		}
+		return int64(0), nil
+	case float64:
+		switch {
+		case x > 0:
+			return 1.0, nil
+		case x < 0:
+			return -1.0, nil
+		}
+		return x, nil
+	case complex128:
+		if x == 0 {
+			return x, nil
+		}
+		return x / complex(cmplx.Abs(x), 0), nil
+	}
+	return nil, newFuncError(name).setUnsupportedArgType(values)
+}
+
+// clamp returns min(max(x, lo), hi), which has the same type as the returned argument.
+func clamp(values []any) (any, error) {
+	name := "clamp"
+	if err := argNumEq(name, values, 3); err != nil {
+		return nil, err
+	}
+
+	lower, err := maxNum(values[:2])
+	if err != nil {
+		return nil, newFuncError(name).setUnsupportedArgType(values)
+	}
+	v, err := minNum([]any{lower, values[2]})
+	if err != nil {
+		return nil, newFuncError(name).setUnsupportedArgType(values)
+	}
+	return v, nil
+}
+
+// intArgs converts arguments to int64, it reports false if any argument isn't an integer.
+func intArgs(values []any) ([]int64, bool) {
+	args := make([]int64, len(values))
+	for i, v := range values {
+		x, ok := integralNumber(ConvertToNumber(v))
+		if !ok {
+			return nil, false
+		}
+		args[i] = x
+	}
+	return args, true
+}
+
+// integralNumber converts x to int64 if it's int64, or float64 without fractional part in range of int64.
+func integralNumber(x any) (int64, bool) {
+	switch x := x.(type) {
+	case int64:
+		return x, true
+	case float64:
+		if x == math.Trunc(x) && x >= math.MinInt64 && x < -math.MinInt64 {
+			return int64(x), true
+		}
+	}
+	return 0, false
+}
+
+// uint64Number converts x to int64 if it fits, otherwise to float64.
+func uint64Number(x uint64) any {
+	if x > math.MaxInt64 {
+		return float64(x)
+	}
+	return int64(x)
+}
+
+func absUint64(x int64) uint64 {
+	if x < 0 {
+		return -uint64(x)
+	}
+	return uint64(x)
+}
+
+func gcdUint64(x, y uint64) uint64 {
+	for y != 0 {
+		x, y = y, x%y
+	}
+	return x
+}
+
+func gcd(values []any) (any, error) {
+	name := "gcd"
+	if err := argNumGe(name, values, 2); err != nil {
+		return nil, err
+	}
+
+	args, ok := intArgs(values)
+	if !ok {
+		return nil, newFuncError(name).setUnsupportedArgType(values)
+	}
+	var d uint64
+	for _, x := range args {
+		d = gcdUint64(d, absUint64(x))
+	}
+	return uint64Number(d), nil
+}
+
+// lcm returns the least common multiple, it's float64 if it overflows int64.
+func lcm(values []any) (any, error) {
+	name := "lcm"
+	if err := argNumGe(name, values, 2); err != nil {
+		return nil, err
+	}
+
+	args, ok := intArgs(values)
+	if !ok {
+		return nil, newFuncError(name).setUnsupportedArgType(values)
+	}
+	ints := make([]*big.Int, len(args))
+	for i, x := range args {
+		ints[i] = big.NewInt(x)
+	}
+	return inexactNumber(lcmBig(ints)), nil
+}
+
+// lcmBig returns the non-negative least common multiple of integers.
+func lcmBig(args []*big.Int) *big.Int {
+	m := big.NewInt(1)
+	for _, x := range args {
+		if x.Sign() == 0 {
+			return new(big.Int)
+		}
+		d := new(big.Int).GCD(nil, nil, m, new(big.Int).Abs(x))
+		m.Mul(m.Quo(m, d), new(big.Int).Abs(x))
+	}
+	return m
+}
+
+func fact(values []any) (any, error) {
+	name := "fact"
+	if err := argNumEq(name, values, 1); err != nil {
+		return nil, err
+	}
+
+	// integral floats are factorials of integers, and negative numbers are rejected for every type
+	x := ConvertToNumber(values[0])
+	if n, ok := integralNumber(x); ok {
+		x = n
+	}
+	switch x := x.(type) {
+	case int64:
+		if x < 0 {
+			return nil, invalidArg(name, x)
+		}
+		v := int64(1)
+		for i := int64(2); i <= x; i++ {
+			if v > math.MaxInt64/i {
+				return math.Gamma(float64(x) + 1), nil
+			}
+			v *= i
+		}
+		return v, nil
+	case float64:
+		if x < 0 {
+			return nil, invalidArg(name, x)
+		}
+		return math.Gamma(x + 1), nil
+	}
+	return nil, newFuncError(name).setUnsupportedArgType(values)
+}
+
+// bit returns 1<<n, it's float64 if it overflows int64.
+func bit(values []any) (any, error) {
+	name := "bit"
+	if err := argNumEq(name, values, 1); err != nil {
+		return nil, err
+	}
+
+	n, ok := ConvertToNumber(values[0]).(int64)
+	switch {
+	case !ok:
+		return nil, newFuncError(name).setUnsupportedArgType(values)
+	case n < 0:
+		return nil, invalidArg(name, n)
+	case n < 63:
+		return int64(1) << n, nil
+	case n > 1024: // exceeds the exponent range of float64
+		return math.Inf(1), nil
+	}
+	return math.Ldexp(1, int(n)), nil
+}
+
+// bitCount returns a function counting bits of the 64-bit two's complement of integers.
+func bitCount(name string, count func(uint64) int) Func {
+	return func(values []any) (any, error) {
+		if err := argNumEq(name, values, 1); err != nil {
+			return nil, err
+		}
+
+		x, ok := ConvertToNumber(values[0]).(int64)
+		if !ok {
+			return nil, newFuncError(name).setUnsupportedArgType(values)
+		}
+		return int64(count(uint64(x))), nil
+	}
+}
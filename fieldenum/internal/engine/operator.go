@@ -1,4 +1,4 @@
-package fieldenum
+package engine
 
 import (
 	"go/token"
@@ -15,6 +15,7 @@ var (
 		token.ADD: pos,
 		token.SUB: neg,
 		token.XOR: not,
+		token.NOT: logicalNot,
 	}
 
 	binaryOps = map[token.Token]binaryFunc{
@@ -29,11 +30,19 @@ var (
 		token.SHL:     shl,
 		token.SHR:     shr,
 		token.AND_NOT: andNot,
+		token.EQL:     compareOp(token.EQL),
+		token.NEQ:     compareOp(token.NEQ),
+		token.LSS:     compareOp(token.LSS),
+		token.LEQ:     compareOp(token.LEQ),
+		token.GTR:     compareOp(token.GTR),
+		token.GEQ:     compareOp(token.GEQ),
+		token.LAND:    logicalAnd,
+		token.LOR:     logicalOr,
 	}
 )
 
 func pos(vx any) (any, error) {
-	switch x := convertToNumber(vx).(type) {
+	switch x := ConvertToNumber(vx).(type) {
 	case int64, float64, complex128:
 		return x, nil
 	}
@@ -41,7 +50,7 @@ func pos(vx any) (any, error) {
 }
 
 func neg(vx any) (any, error) {
-	switch x := convertToNumber(vx).(type) {
+	switch x := ConvertToNumber(vx).(type) {
 	case int64:
 		return negInt64(x), nil
 	case float64:
@@ -53,7 +62,7 @@ func neg(vx any) (any, error) {
 }
 
 func not(vx any) (any, error) {
-	x, ok := convertToNumber(vx).(int64)
+	x, ok := ConvertToNumber(vx).(int64)
 	if !ok {
 		return nil, newOpError("^").setUnaryType(vx)
 	}
@@ -61,8 +70,12 @@ func not(vx any) (any, error) {
 }
 
 func add(vx, vy any) (any, error) {
-	vx, vy = convertToNumber(vx), convertToNumber(vy)
+	vx, vy = ConvertToNumber(vx), ConvertToNumber(vy)
 	switch x := vx.(type) {
+	case string:
+		if y, ok := vy.(string); ok {
+			return x + y, nil
+		}
 	case int64:
 		switch y := vy.(type) {
 		case int64:
@@ -95,7 +108,7 @@ func add(vx, vy any) (any, error) {
 }
 
 func sub(vx, vy any) (any, error) {
-	vx, vy = convertToNumber(vx), convertToNumber(vy)
+	vx, vy = ConvertToNumber(vx), ConvertToNumber(vy)
 	switch x := vx.(type) {
 	case int64:
 		switch y := vy.(type) {
@@ -129,7 +142,7 @@ func sub(vx, vy any) (any, error) {
 }
 
 func mul(vx, vy any) (any, error) {
-	vx, vy = convertToNumber(vx), convertToNumber(vy)
+	vx, vy = ConvertToNumber(vx), ConvertToNumber(vy)
 	switch x := vx.(type) {
 	case int64:
 		switch y := vy.(type) {
@@ -163,7 +176,7 @@ func mul(vx, vy any) (any, error) {
 }
 
 func div(vx, vy any) (any, error) {
-	vx, vy = convertToNumber(vx), convertToNumber(vy)
+	vx, vy = ConvertToNumber(vx), ConvertToNumber(vy)
 	switch x := vx.(type) {
 	case int64:
 		switch y := vy.(type) {
@@ -197,7 +210,7 @@ func div(vx, vy any) (any, error) {
 }
 
 func mod(vx, vy any) (any, error) {
-	vx, vy = convertToNumber(vx), convertToNumber(vy)
+	vx, vy = ConvertToNumber(vx), ConvertToNumber(vy)
 	switch x := vx.(type) {
 	case int64:
 		switch y := vy.(type) {
@@ -218,7 +231,7 @@ func mod(vx, vy any) (any, error) {
 }
 
 func and(vx, vy any) (any, error) {
-	vx, vy = convertToNumber(vx), convertToNumber(vy)
+	vx, vy = ConvertToNumber(vx), ConvertToNumber(vy)
 	x, ok := vx.(int64)
 	if !ok {
 		return nil, newOpError("&").setBinaryType(vx, vy)
@@ -231,7 +244,7 @@ func and(vx, vy any) (any, error) {
 }
 
 func or(vx, vy any) (any, error) {
-	vx, vy = convertToNumber(vx), convertToNumber(vy)
+	vx, vy = ConvertToNumber(vx), ConvertToNumber(vy)
 	x, ok := vx.(int64)
 	if !ok {
 		return nil, newOpError("|").setBinaryType(vx, vy)
@@ -244,7 +257,7 @@ func or(vx, vy any) (any, error) {
 }
 
 func xor(vx, vy any) (any, error) {
-	vx, vy = convertToNumber(vx), convertToNumber(vy)
+	vx, vy = ConvertToNumber(vx), ConvertToNumber(vy)
 	x, ok := vx.(int64)
 	if !ok {
 		return nil, newOpError("^").setBinaryType(vx, vy)
@@ -257,7 +270,7 @@ func xor(vx, vy any) (any, error) {
 }
 
 func shl(vx, vy any) (any, error) {
-	vx, vy = convertToNumber(vx), convertToNumber(vy)
+	vx, vy = ConvertToNumber(vx), ConvertToNumber(vy)
 	x, ok := vx.(int64)
 	if !ok {
 		return nil, newOpError("<<").setBinaryType(vx, vy)
@@ -273,7 +286,7 @@ func shl(vx, vy any) (any, error) {
 }
 
 func shr(vx, vy any) (any, error) {
-	vx, vy = convertToNumber(vx), convertToNumber(vy)
+	vx, vy = ConvertToNumber(vx), ConvertToNumber(vy)
 	x, ok := vx.(int64)
 	if !ok {
 		return nil, newOpError(">>").setBinaryType(vx, vy)
@@ -289,7 +302,7 @@ func shr(vx, vy any) (any, error) {
 }
 
 func andNot(vx, vy any) (any, error) {
-	vx, vy = convertToNumber(vx), convertToNumber(vy)
+	vx, vy = ConvertToNumber(vx), ConvertToNumber(vy)
 	x, ok := vx.(int64)
 	if !ok {
 		return nil, newOpError("&^").setBinaryType(vx, vy)
@@ -300,3 +313,114 @@ func andNot(vx, vy any) (any, error) {
 	}
 	return x &^ y, nil
 }
+
+func logicalNot(vx any) (any, error) {
+	x, ok := vx.(bool)
+	if !ok {
+		return nil, newOpError("!").setUnaryType(vx)
+	}
+	return !x, nil
+}
+
+func logicalAnd(vx, vy any) (any, error) {
+	x, ok := vx.(bool)
+	if !ok {
+		return nil, newOpError("&&").setBinaryType(vx, vy)
+	}
+	y, ok := vy.(bool)
+	if !ok {
+		return nil, newOpError("&&").setBinaryType(vx, vy)
+	}
+	return x && y, nil
+}
+
+func logicalOr(vx, vy any) (any, error) {
+	x, ok := vx.(bool)
+	if !ok {
+		return nil, newOpError("||").setBinaryType(vx, vy)
+	}
+	y, ok := vy.(bool)
+	if !ok {
+		return nil, newOpError("||").setBinaryType(vx, vy)
+	}
+	return x || y, nil
+}
+
+// compareOp returns the comparison operator of tok.
+// int64 and float64 operands are compared as float64 if their types differ,
+// complex128 and bool operands can only be compared for equality,
+// and string operands can only be compared with string operands.
+func compareOp(tok token.Token) binaryFunc {
+	op := tok.String()
+	isEquality := tok == token.EQL || tok == token.NEQ
+	return func(vx, vy any) (any, error) {
+		vx, vy = ConvertToNumber(vx), ConvertToNumber(vy)
+		switch x := vx.(type) {
+		case int64:
+			switch y := vy.(type) {
+			case int64:
+				return compareOrdered(tok, x, y), nil
+			case float64:
+				return compareOrdered(tok, float64(x), y), nil
+			case complex128:
+				if isEquality {
+					return compareEquality(tok, complex(float64(x), 0), y), nil
+				}
+			}
+		case float64:
+			switch y := vy.(type) {
+			case int64:
+				return compareOrdered(tok, x, float64(y)), nil
+			case float64:
+				return compareOrdered(tok, x, y), nil
+			case complex128:
+				if isEquality {
+					return compareEquality(tok, complex(x, 0), y), nil
+				}
+			}
+		case complex128:
+			if !isEquality {
+				break
+			}
+			switch y := vy.(type) {
+			case int64:
+				return compareEquality(tok, x, complex(float64(y), 0)), nil
+			case float64:
+				return compareEquality(tok, x, complex(y, 0)), nil
+			case complex128:
+				return compareEquality(tok, x, y), nil
+			}
+		case bool:
+			if y, ok := vy.(bool); ok && isEquality {
+				return compareEquality(tok, x, y), nil
+			}
+		case string:
+			if y, ok := vy.(string); ok {
+				return compareOrdered(tok, x, y), nil
+			}
+		}
+		return nil, newOpError(op).setBinaryType(vx, vy)
+	}
+}
+
+func compareOrdered[T int64 | float64 | string](tok token.Token, x, y T) bool {
+	switch tok {
+	case token.LSS:
+		return x < y
+	case token.LEQ:
+		return x <= y
+	case token.GTR:
+		return x > y
+	case token.GEQ:
+		return x >= y
+	default:
+		return compareEquality(tok, x, y)
+	}
+}
+
+func compareEquality[T comparable](tok token.Token, x, y T) bool {
+	if tok == token.NEQ {
+		return x != y
+	}
+	return x == y
+}
@@ -0,0 +1,418 @@
+package engine
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"math/big"
+	"strconv"
+	"sync"
+)
+
+// Program is a compiled expression, which can be evaluated many times.
+// A Program is safe for concurrent use.
+type Program struct {
+	Expr  string
+	fset  *token.FileSet
+	eval  evalFunc
+	depth int       // nesting depth of the syntax tree
+	nodes int       // number of nodes of the syntax tree
+	start token.Pos // start of the expression without prefix
+	end   token.Pos // end of the expression without suffix
+
+	// traced is like eval but reports to Config.Trace, it's compiled on the first traced evaluation,
+	// so that evaluations without Trace don't pay for it
+	traced    evalFunc
+	traceOnce sync.Once
+	tree      ast.Expr
+	compiler  compiler
+}
+
+// evalFunc evaluates a compiled node.
+type evalFunc = func(conf *Config) (any, *ExprError)
+
+// compiler compiles a syntax tree to a closure tree.
+type compiler struct {
+	expr     string // source expression, in which "if" keywords aren't replaced
+	fset     *token.FileSet
+	conds    map[int]bool // offsets of "if" keywords used as conditional functions
+	start    token.Pos    // nodes in [start, end) count for Limits
+	end      token.Pos
+	depth    int  // depth of the node being compiled
+	maxDepth int  // maximum depth of compiled nodes
+	nodes    int  // number of compiled nodes
+	trace    bool // nodes report to Config.Trace
+}
+
+// Parse parses an expression following Go syntax and compiles it to a closure tree,
+// so that evaluations don't walk the syntax tree again.
+func Parse(expr string) (*Program, error) { return ParseWrapped("", expr, "") }
+
+// ParseWrapped parses the expression prefix+expr+suffix like Parse,
+// but only nodes of expr count for Limits, and errors of Limits locate expr.
+func ParseWrapped(prefix, expr, suffix string) (*Program, error) {
+	full := prefix + expr + suffix
+	src, conds := replaceConds(full)
+	fset := token.NewFileSet()
+	tree, err := parser.ParseExprFrom(fset, "", src, 0)
+	if err != nil {
+		return nil, newExprError().setExpr(full)
+	}
+	base := token.Pos(fset.Base() - len(src) - 1)
+	c := &compiler{expr: full, fset: fset, conds: conds, start: base + token.Pos(len(prefix)), end: base + token.Pos(len(prefix)+len(expr))}
+	eval := c.compileNode(tree)
+	return &Program{Expr: full, fset: fset, eval: eval, depth: c.maxDepth, nodes: c.nodes, start: c.start, end: c.end, tree: tree, compiler: *c}, nil
+}
+
+// replaceConds replaces "if" keywords followed by "(" with an identifier of the same length,
+// so that if(cond, x, y) can be parsed as a function call.
+func replaceConds(expr string) (string, map[int]bool) {
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	file := fset.AddFile("", -1, len(expr))
+	s.Init(file, []byte(expr), nil, 0)
+
+	var src []byte
+	var conds map[int]bool
+	for prev := token.Pos(-1); ; {
+		pos, tok, _ := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.LPAREN && prev >= 0 {
+			if src == nil {
+				src, conds = []byte(expr), make(map[int]bool)
+			}
+			offset := file.Offset(prev)
+			copy(src[offset:], "iF")
+			conds[offset] = true
+		}
+		prev = -1
+		if tok == token.IF {
+			prev = pos
+		}
+	}
+	if src == nil {
+		return expr, nil
+	}
+	return string(src), conds
+}
+
+// Eval evaluates the program with identifiers and functions resolved by conf.
+// It returns an *ExprError wrapping a *LimitError if the evaluation exceeds conf.Limits.
+func (p *Program) Eval(conf *Config) (any, error) {
+	if err := conf.Limits.checkProgram(p); err != nil {
+		return nil, newExprError().setErr(err).setPos(p.start, p.end).setFset(p.fset).setExpr(p.Expr)
+	}
+	eval := p.eval
+	if conf.Trace != nil {
+		eval = p.tracedEval()
+	}
+	v, err := eval(conf)
+	if err != nil {
+		pErr := *err
+		return nil, pErr.setFset(p.fset).setExpr(p.Expr)
+	}
+	return v, nil
+}
+
+// tracedEval returns the evaluation reporting to Config.Trace, which is compiled on the first call.
+func (p *Program) tracedEval() evalFunc {
+	p.traceOnce.Do(func() {
+		c := p.compiler
+		c.trace = true
+		p.traced = c.compileNode(p.tree)
+	})
+	return p.traced
+}
+
+func (c *compiler) compileNode(node ast.Node) evalFunc {
+	if pos := node.Pos(); pos >= c.start && pos < c.end {
+		c.nodes++
+		if c.depth++; c.depth > c.maxDepth {
+			c.maxDepth = c.depth
+		}
+		defer func() { c.depth-- }()
+	}
+
+	var eval evalFunc
+	switch n := node.(type) {
+	case *ast.BasicLit: // 处理字面量
+		eval = compileBasicLit(n)
+	case *ast.Ident: // 处理标识符
+		eval = compileIdent(n)
+	case *ast.ParenExpr: // 处理括号表达式
+		return c.compileNode(n.X)
+	case *ast.UnaryExpr: // 处理一元表达式
+		eval = c.compileUnaryExpr(n)
+	case *ast.BinaryExpr: // 处理二元表达式
+		eval = c.compileBinaryExpr(n)
+	case *ast.CallExpr: // 处理函数调用
+		eval = c.compileCallExpr(n)
+	default:
+		return compileError(newExprError().setPos(n.Pos(), n.End()))
+	}
+	if !c.trace {
+		return eval
+	}
+	return c.compileTrace(eval, node)
+}
+
+// compileTrace wraps eval to report the source and the value of the node to conf.Trace.
+func (c *compiler) compileTrace(eval evalFunc, node ast.Node) evalFunc {
+	src := c.expr[c.fset.Position(node.Pos()).Offset:c.fset.Position(node.End()).Offset]
+	return func(conf *Config) (any, *ExprError) {
+		v, err := eval(conf)
+		if err == nil {
+			conf.Trace(src, v)
+		}
+		return v, err
+	}
+}
+
+func compileError(pErr *ExprError) evalFunc {
+	return func(*Config) (any, *ExprError) { return nil, pErr }
+}
+
+func compileBasicLit(lit *ast.BasicLit) evalFunc {
+	var v, exactV any
+	var err error
+	switch lit.Kind {
+	case token.INT:
+		x, ok := new(big.Int).SetString(lit.Value, 0)
+		if !ok {
+			err = errors.New(`invalid literal "` + lit.Value + `"`)
+		} else if v, exactV = x.Int64(), x; !x.IsInt64() {
+			v, _ = new(big.Float).SetInt(x).Float64()
+		}
+	case token.FLOAT:
+		v, err = strconv.ParseFloat(lit.Value, 64)
+		if x, ok := new(big.Rat).SetString(lit.Value); ok {
+			exactV = x
+		}
+	case token.IMAG:
+		v, err = strconv.ParseComplex(lit.Value, 128)
+	case token.STRING:
+		v, err = strconv.Unquote(lit.Value)
+	default:
+		err = errors.New(`invalid literal "` + lit.Value + `"`)
+	}
+
+	if err != nil {
+		return compileError(newExprError().setErr(err))
+	}
+	if exactV == nil {
+		exactV = v
+	}
+	return func(conf *Config) (any, *ExprError) {
+		if conf.Exact {
+			return exactV, nil
+		}
+		return v, nil
+	}
+}
+
+func compileIdent(ident *ast.Ident) evalFunc {
+	name, start, end := ident.Name, ident.Pos(), ident.End()
+	return func(conf *Config) (any, *ExprError) {
+		if v, ok := conf.Value(name); ok {
+			return v, nil
+		}
+		if conf.Lookup != nil {
+			v, err := conf.Lookup(name)
+			if err != nil {
+				return nil, newExprError().setErr(err).setPos(start, end)
+			}
+			if v != nil {
+				return v, nil
+			}
+		}
+		err := errors.New(`unsupported identifier "` + name + `"`)
+		return nil, newExprError().setErr(err)
+	}
+}
+
+func (c *compiler) compileUnaryExpr(expr *ast.UnaryExpr) evalFunc {
+	evalX, start, end := c.compileNode(expr.X), expr.Pos(), expr.X.End()
+	op, ok := unaryOps[expr.Op]
+	exactOp, hasExactOp := exactUnaryOps[expr.Op]
+	opStr := expr.Op.String()
+
+	return func(conf *Config) (any, *ExprError) {
+		x, pErr := evalX(conf)
+		if pErr != nil {
+			return nil, pErr
+		}
+
+		if conf.Exact {
+			if hasExactOp {
+				if v, ok, err := exactOp(x); ok {
+					if err != nil {
+						return nil, newExprError().setErr(err).setPos(start, end)
+					}
+					return v, nil
+				}
+			}
+			x = inexactNumber(x)
+		}
+
+		if !ok {
+			opErr := newOpError(opStr).setUnsupported()
+			return nil, newExprError().setErr(opErr).setPos(start, end)
+		}
+
+		v, err := op(x)
+		if err != nil {
+			return nil, newExprError().setErr(err).setPos(start, end)
+		}
+		return v, nil
+	}
+}
+
+func (c *compiler) compileBinaryExpr(expr *ast.BinaryExpr) evalFunc {
+	evalX, evalY, start, end := c.compileNode(expr.X), c.compileNode(expr.Y), expr.X.Pos(), expr.Y.End()
+	op, ok := binaryOps[expr.Op]
+	exactOp, hasExactOp := exactBinaryOps[expr.Op]
+	opStr := expr.Op.String()
+	// the result of "false && y" is false, and the result of "true || y" is true
+	var shortCircuit any
+	switch expr.Op {
+	case token.LAND:
+		shortCircuit = false
+	case token.LOR:
+		shortCircuit = true
+	}
+
+	return func(conf *Config) (any, *ExprError) {
+		x, pErr := evalX(conf)
+		if pErr != nil {
+			return nil, pErr
+		}
+		if cond, isBool := x.(bool); isBool && (shortCircuit == cond) {
+			return cond, nil
+		}
+		y, pErr := evalY(conf)
+		if pErr != nil {
+			return nil, pErr
+		}
+		if err := conf.Limits.checkContext(); err != nil {
+			return nil, newExprError().setErr(err).setPos(start, end)
+		}
+
+		if conf.Exact {
+			if hasExactOp {
+				if v, ok, err := exactOp(x, y); ok {
+					if err != nil {
+						return nil, newExprError().setErr(err).setPos(start, end)
+					}
+					return v, nil
+				}
+			}
+			x, y = inexactNumber(x), inexactNumber(y)
+		}
+
+		if !ok {
+			opErr := newOpError(opStr).setUnsupported()
+			return nil, newExprError().setErr(opErr).setPos(start, end)
+		}
+
+		v, err := op(x, y)
+		if err != nil {
+			return nil, newExprError().setErr(err).setPos(start, end)
+		}
+		return v, nil
+	}
+}
+
+func (c *compiler) compileCallExpr(expr *ast.CallExpr) evalFunc {
+	start, end := expr.Pos(), expr.End()
+	funcExpr, ok := expr.Fun.(*ast.Ident)
+	if !ok {
+		return compileError(newExprError().setPos(start, end))
+	}
+	fnName := funcExpr.Name
+
+	evalArgs := make([]evalFunc, len(expr.Args))
+	for i, arg := range expr.Args {
+		evalArgs[i] = c.compileNode(arg)
+	}
+	if c.conds[c.fset.Position(funcExpr.Pos()).Offset] {
+		return compileCond(evalArgs, start, end)
+	}
+
+	return func(conf *Config) (any, *ExprError) {
+		fn, ok := conf.Func(fnName)
+		if !ok {
+			fnErr := newFuncError(fnName).setNotExisted()
+			return nil, newExprError().setErr(fnErr).setPos(start, end)
+		}
+		if err := conf.Limits.checkFunc(fnName); err != nil {
+			return nil, newExprError().setErr(err).setPos(start, end)
+		}
+
+		args := make([]any, len(evalArgs))
+		for i, evalArg := range evalArgs {
+			v, pErr := evalArg(conf)
+			if pErr != nil {
+				return nil, pErr
+			}
+			args[i] = v
+		}
+		if err := conf.Limits.checkContext(); err != nil {
+			return nil, newExprError().setErr(err).setPos(start, end)
+		}
+
+		if conf.Exact {
+			// registered functions shadowing built-in functions aren't evaluated exactly
+			exactFn, hasExactFn := exactFuncs[fnName]
+			if _, registered := conf.Funcs[fnName]; hasExactFn && !registered {
+				if v, ok, err := exactFn(args); ok {
+					if err != nil {
+						return nil, newExprError().setErr(err).setPos(start, end)
+					}
+					return v, nil
+				}
+			}
+			args = inexactNumbers(args)
+		}
+
+		v, err := fn(args)
+		if err != nil {
+			// errors of adapted functions don't know the name which the function is registered with
+			var fnErr *FuncError
+			if errors.As(err, &fnErr) && fnErr.Func == "" {
+				fnErr.Func = fnName
+			}
+			return nil, newExprError().setErr(err).setPos(start, end)
+		}
+		return v, nil
+	}
+}
+
+// compileCond compiles the conditional function if(cond, x, y),
+// which evaluates only one of x and y depending on the boolean cond.
+func compileCond(evalArgs []evalFunc, start, end token.Pos) evalFunc {
+	return func(conf *Config) (any, *ExprError) {
+		if len(evalArgs) != 3 {
+			args := make([]any, len(evalArgs))
+			fnErr := newFuncError("if").setNum(args, 3)
+			return nil, newExprError().setErr(fnErr).setPos(start, end)
+		}
+
+		v, pErr := evalArgs[0](conf)
+		if pErr != nil {
+			return nil, pErr
+		}
+		cond, ok := v.(bool)
+		if !ok {
+			fnErr := newFuncError("if").setUnsupportedArgType([]any{v})
+			return nil, newExprError().setErr(fnErr).setPos(start, end)
+		}
+		if cond {
+			return evalArgs[1](conf)
+		}
+		return evalArgs[2](conf)
+	}
+}
@@ -1,7 +1,9 @@
-package fieldenum
+package engine
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"math"
 	"math/cmplx"
 	"testing"
@@ -9,10 +11,11 @@ import (
 
 type parseTest struct {
 	expr   string
-	funcs  map[string]ExprFunc
+	funcs  map[string]Func
 	values map[string]any
 	want   any
 	retErr bool
+	exact  bool // evaluate in exact arithmetic, want is the formatted result
 }
 
 func gotEqlWant(got, want any) bool {
@@ -21,20 +24,24 @@ func gotEqlWant(got, want any) bool {
 
 func testParse(t *testing.T, i int, test parseTest) {
 	var got any
-	fset, tree, err := parse(test.expr)
+	prog, err := Parse(test.expr)
 	if err == nil {
 		if test.funcs == nil {
-			test.funcs = make(map[string]ExprFunc)
+			test.funcs = make(map[string]Func)
 		}
 		if test.values == nil {
 			test.values = make(map[string]any)
 		}
 
-		conf := &config{
-			funcs:  test.funcs,
-			values: test.values,
+		conf := &Config{
+			Funcs:  test.funcs,
+			Values: test.values,
+			Exact:  test.exact,
+		}
+		got, err = prog.Eval(conf)
+		if test.exact && err == nil {
+			got = fmt.Sprint(got)
 		}
-		got, err = eval(conf, test.expr, fset, tree)
 	}
 
 	switch {
@@ -70,7 +77,7 @@ func TestParse(t *testing.T) {
 			13: {expr: "", retErr: true},
 			14: {expr: "a", retErr: true},
 			15: {expr: "'a'", retErr: true},
-			16: {expr: "iota", want: nil},
+			16: {expr: "iota", retErr: true},
 			17: {expr: "iota", values: map[string]any{"iota": int64(1)}, want: int64(1)},
 			18: {expr: "9223372036854775808", want: 9223372036854775808.0},
 			19: {expr: "-9223372036854775809", want: -9223372036854775809.0},
@@ -189,6 +196,157 @@ func TestParse(t *testing.T) {
 		}
 	})
 
+	t.Run("booleans", func(t *testing.T) {
+		iota3 := map[string]any{"iota": int64(3)}
+		tests := []parseTest{
+			0:  {expr: "true", want: true},
+			1:  {expr: "!false", want: true},
+			2:  {expr: "1 == 1", want: true},
+			3:  {expr: "1 != 1.0", want: false},
+			4:  {expr: "1 < 2", want: true},
+			5:  {expr: "1 <= 0.5", want: false},
+			6:  {expr: "2.5 > 2", want: true},
+			7:  {expr: "2 >= 2", want: true},
+			8:  {expr: "1i == complex(0, 1)", want: true},
+			9:  {expr: "1 != 1+0i", want: false},
+			10: {expr: "nan == nan", want: false},
+			11: {expr: "nan != nan", want: true},
+			12: {expr: "true == (1 < 2)", want: true},
+			13: {expr: "true && false", want: false},
+			14: {expr: "false || true", want: true},
+			15: {expr: "false && a", want: false},
+			16: {expr: "true || a", want: true},
+			17: {expr: "iota < 3 || iota > 5", values: iota3, want: false},
+			18: {expr: "int(true)", want: int64(1)},
+			19: {expr: "float(false)", want: 0.0},
+			20: {expr: "if(iota < 3, 1, 2)", values: iota3, want: int64(2)},
+			21: {expr: "if(iota >= 3, 1, a)", values: iota3, want: int64(1)},
+			22: {expr: "if(true, if(false, 1, 2), 3)", want: int64(2)},
+			23: {expr: "!1", retErr: true},
+			24: {expr: "1 && true", retErr: true},
+			25: {expr: "true || 1", want: true},
+			26: {expr: "false || 1", retErr: true},
+			27: {expr: "1i < 2i", retErr: true},
+			28: {expr: "true < false", retErr: true},
+			29: {expr: "true == 1", retErr: true},
+			30: {expr: "true + 1", retErr: true},
+			31: {expr: "if(1, 2, 3)", retErr: true},
+			32: {expr: "if(true, 2)", retErr: true},
+			33: {expr: "if(true, 1, 2, 3)", retErr: true},
+			34: {expr: "if", retErr: true},
+			35: {expr: "iF(true, 1, 2)", retErr: true},
+		}
+
+		for i, test := range tests {
+			testParse(t, i, test)
+		}
+	})
+
+	t.Run("strings", func(t *testing.T) {
+		nameValues := map[string]any{"name": "HTTPStatusOK", "iota": int64(7)}
+		tests := []parseTest{
+			0:  {expr: `"a"`, want: "a"},
+			1:  {expr: "`a\\b`", want: `a\b`},
+			2:  {expr: `"a" + "b"`, want: "ab"},
+			3:  {expr: `"a" == "a"`, want: true},
+			4:  {expr: `"a" < "b"`, want: true},
+			5:  {expr: `lower("AbC")`, want: "abc"},
+			6:  {expr: `upper("AbC")`, want: "ABC"},
+			7:  {expr: `snake(name)`, values: nameValues, want: "http_status_ok"},
+			8:  {expr: `kebab(name)`, values: nameValues, want: "http-status-ok"},
+			9:  {expr: `snake("UserID2Name")`, want: "user_id2_name"},
+			10: {expr: `snake("__a  b__")`, want: "a_b"},
+			11: {expr: `"status_" + snake(name)`, values: nameValues, want: "status_http_status_ok"},
+			12: {expr: `fmt("0x%04X", iota)`, values: nameValues, want: "0x0007"},
+			13: {expr: `fmt("%s-%v", name, 1.5)`, values: nameValues, want: "HTTPStatusOK-1.5"},
+			14: {expr: `if(iota > 5, "big", "small")`, values: nameValues, want: "big"},
+			15: {expr: `"a" + 1`, retErr: true},
+			16: {expr: `"a" - "b"`, retErr: true},
+			17: {expr: `"a" == 1`, retErr: true},
+			18: {expr: `lower(1)`, retErr: true},
+			19: {expr: `upper()`, retErr: true},
+			20: {expr: `snake("a", "b")`, retErr: true},
+			21: {expr: `kebab(1)`, retErr: true},
+			22: {expr: `fmt()`, retErr: true},
+			23: {expr: `fmt(1)`, retErr: true},
+			24: {expr: `'a'`, retErr: true},
+		}
+
+		for i, test := range tests {
+			testParse(t, i, test)
+		}
+	})
+
+	t.Run("exact", func(t *testing.T) {
+		tests := []parseTest{
+			0:  {expr: "1", want: "1"},
+			1:  {expr: "0xFF", want: "255"},
+			2:  {expr: "1.5", want: "3/2"},
+			3:  {expr: "1<<63", want: "9223372036854775808"},
+			4:  {expr: "1<<64 - 1", want: "18446744073709551615"},
+			5:  {expr: "9223372036854775807 + 1", want: "9223372036854775808"},
+			6:  {expr: "-9223372036854775808 - 1", want: "-9223372036854775809"},
+			7:  {expr: "3037000500 * 3037000500", want: "9223372037000250000"},
+			8:  {expr: "7 / 2", want: "3"},
+			9:  {expr: "7 / 2.0", want: "7/2"},
+			10: {expr: "0.1 + 0.2", want: "3/10"},
+			11: {expr: "0.1 + 0.2 == 0.3", want: "true"},
+			12: {expr: "-7 % 3", want: "-1"},
+			13: {expr: "^0", want: "-1"},
+			14: {expr: "(1<<70) >> 68", want: "4"},
+			15: {expr: "(1<<64 | 1<<63) &^ (1<<63)", want: "18446744073709551616"},
+			16: {expr: "(1<<64) | 1", want: "18446744073709551617"},
+			17: {expr: "pow(2, 100)", want: "1267650600228229401496703205376"},
+			18: {expr: "pow(2, -2)", want: "1/4"},
+			19: {expr: "pow(0.5, 2)", want: "1/4"},
+			20: {expr: "int(7/2.0)", want: "3"},
+			21: {expr: "float(3)", want: "3/1"},
+			22: {expr: "abs(-(1<<70))", want: "1180591620717411303424"},
+			23: {expr: "max(1, 1<<70, 2.5)", want: "1180591620717411303424"},
+			24: {expr: "min(1, 1<<70, 2.5)", want: "1"},
+			25: {expr: "iota + 1", values: map[string]any{"iota": int64(1)}, want: "2"},
+			26: {expr: "1 + 1i", want: "(1+1i)"},
+			27: {expr: "sqrt(4)", want: "2"},
+			28: {expr: `"a" + "b"`, want: "ab"},
+			29: {expr: "1 / 0", retErr: true},
+			30: {expr: "1.0 / 0", retErr: true},
+			31: {expr: "1 % 0", retErr: true},
+			32: {expr: "1 << -1", retErr: true},
+			33: {expr: "1 << 100000", retErr: true},
+			34: {expr: "inf > 1<<70", want: "true"},
+			35: {expr: "uint64() + 1", funcs: map[string]Func{"uint64": func(values []any) (any, error) {
+				return uint64(math.MaxUint64), nil
+			}}, want: "18446744073709551616"},
+			36: {expr: "floor(-7/2.0)", want: "-4"},
+			37: {expr: "ceil(7/2.0)", want: "4"},
+			38: {expr: "trunc(-7/2.0)", want: "-3"},
+			39: {expr: "round(5/2.0)", want: "3"},
+			40: {expr: "round(-5/2.0)", want: "-3"},
+			41: {expr: "round(1<<70)", want: "1180591620717411303424"},
+			42: {expr: "gcd(1<<70, 1<<65, 96)", want: "32"},
+			43: {expr: "lcm(1<<40, 3<<40)", want: "3298534883328"},
+			44: {expr: "bit(64)", want: "18446744073709551616"},
+			45: {expr: "fact(25)", want: "15511210043330985984000000"},
+			46: {expr: "bit(-1)", retErr: true},
+			47: {expr: "fact(2.5)", want: "3.323350970447843"},
+			48: {expr: "1 >> 100000", want: "0"},
+			49: {expr: "-(1<<70) >> 100000", want: "-1"},
+			50: {expr: "-5 >> 3", want: "-1"},
+			51: {expr: "(1<<70) >> (1<<70)", want: "0"},
+			52: {expr: "1 >> -1", retErr: true},
+			53: {expr: "fact(-1.0)", retErr: true},
+			54: {expr: "gcd(4.0, 6)", want: "2"},
+			55: {expr: "lcm(4.0, 6)", want: "12"},
+		}
+		for i := range tests {
+			tests[i].exact = true
+		}
+
+		for i, test := range tests {
+			testParse(t, i, test)
+		}
+	})
+
 	t.Run("functions", func(t *testing.T) {
 		fn := func(values []any) (any, error) {
 			if len(values) == 0 {
@@ -200,7 +358,7 @@ func TestParse(t *testing.T) {
 		tests := []parseTest{
 			0:   {expr: "int(1)", want: int64(1)},
 			1:   {expr: "int(1.0)", want: int64(1)},
-			2:   {expr: "int(1i)", retErr: true},
+			2:   {expr: "int(2+1i)", want: int64(2)},
 			3:   {expr: "int(inf)", want: int64(math.MaxInt64)},
 			4:   {expr: "int(-inf)", want: int64(math.MinInt64)},
 			5:   {expr: "int(nan)", want: int64(math.MinInt64)},
@@ -209,7 +367,7 @@ func TestParse(t *testing.T) {
 			8:   {expr: "int(1,2)", retErr: true},
 			9:   {expr: "float(1)", want: 1.0},
 			10:  {expr: "float(1.0)", want: 1.0},
-			11:  {expr: "float(1i)", retErr: true},
+			11:  {expr: "float(2.5+1i)", want: 2.5},
 			12:  {expr: "float(inf)", want: math.Inf(1)},
 			13:  {expr: "float(-inf)", want: math.Inf(-1)},
 			14:  {expr: "float(nan)", want: math.NaN()},
@@ -460,9 +618,83 @@ func TestParse(t *testing.T) {
 			259: {expr: "atanh(inf)", want: math.NaN()},
 			260: {expr: "atanh(-inf)", want: math.NaN()},
 			261: {expr: "atanh(nan)", want: math.NaN()},
+			262: {expr: "log2(8)", want: 3.0},
+			263: {expr: "log2(0.5)", want: -1.0},
+			264: {expr: "log2(-1)", want: math.NaN()},
+			265: {expr: "log2(1i)", want: cmplx.Log(1i) / math.Ln2},
+			266: {expr: "log2()", retErr: true},
+			267: {expr: "hypot(3, 4)", want: 5.0},
+			268: {expr: "hypot(3.0, inf)", want: math.Inf(1)},
+			269: {expr: "hypot(1i, 1)", retErr: true},
+			270: {expr: "hypot(1)", retErr: true},
+			271: {expr: "atan2(1, 1)", want: math.Pi / 4},
+			272: {expr: "atan2(0, -1.0)", want: math.Pi},
+			273: {expr: `atan2("a", 1)`, retErr: true},
+			274: {expr: "floor(2)", want: int64(2)},
+			275: {expr: "floor(-2.5)", want: -3.0},
+			276: {expr: "floor(1.5+2.5i)", want: 1 + 2i},
+			277: {expr: "floor(nan)", want: math.NaN()},
+			278: {expr: "ceil(2.1)", want: 3.0},
+			279: {expr: "ceil(-2.5)", want: -2.0},
+			280: {expr: "round(2.5)", want: 3.0},
+			281: {expr: "round(-2.5)", want: -3.0},
+			282: {expr: "round(2.4)", want: 2.0},
+			283: {expr: "trunc(-2.7)", want: -2.0},
+			284: {expr: "trunc(inf)", want: math.Inf(1)},
+			285: {expr: `round("a")`, retErr: true},
+			286: {expr: "round(1, 2)", retErr: true},
+			287: {expr: "sign(-5)", want: int64(-1)},
+			288: {expr: "sign(0)", want: int64(0)},
+			289: {expr: "sign(2.5)", want: 1.0},
+			290: {expr: "sign(-inf)", want: -1.0},
+			291: {expr: "sign(nan)", want: math.NaN()},
+			292: {expr: "sign(3i)", want: 1i},
+			293: {expr: "sign()", retErr: true},
+			294: {expr: "clamp(5, 0, 3)", want: int64(3)},
+			295: {expr: "clamp(-1, 0.5, 3)", want: 0.5},
+			296: {expr: "clamp(2, 0, 3)", want: int64(2)},
+			297: {expr: "clamp(1i, 0, 3)", retErr: true},
+			298: {expr: "clamp(1, 2)", retErr: true},
+			299: {expr: "gcd(12, 18)", want: int64(6)},
+			300: {expr: "gcd(-12, 18, 8)", want: int64(2)},
+			301: {expr: "gcd(0, 0)", want: int64(0)},
+			302: {expr: "gcd(-9223372036854775807-1, 0)", want: 9223372036854775808.0},
+			303: {expr: "gcd(1.5, 3)", retErr: true},
+			304: {expr: "gcd(1)", retErr: true},
+			305: {expr: "lcm(4, 6)", want: int64(12)},
+			306: {expr: "lcm(-4, 6, 10)", want: int64(60)},
+			307: {expr: "lcm(0, 6)", want: int64(0)},
+			308: {expr: "lcm(1<<40, 3<<40)", want: int64(3 << 40)},
+			309: {expr: "lcm(9223372036854775807, 9223372036854775806)", want: 8.507059173023462e37},
+			310: {expr: "lcm(1.0, 2)", want: int64(2)},
+			311: {expr: "fact(0)", want: int64(1)},
+			312: {expr: "fact(20)", want: int64(2432902008176640000)},
+			313: {expr: "fact(21)", want: math.Gamma(22)},
+			314: {expr: "fact(171)", want: math.Inf(1)},
+			315: {expr: "fact(0.5)", want: math.Gamma(1.5)},
+			316: {expr: "fact(-1)", retErr: true},
+			317: {expr: "bit(0)", want: int64(1)},
+			318: {expr: "bit(62)", want: int64(1 << 62)},
+			319: {expr: "bit(63)", want: 9223372036854775808.0},
+			320: {expr: "bit(2000)", want: math.Inf(1)},
+			321: {expr: "bit(-1)", retErr: true},
+			322: {expr: "bit(1.0)", retErr: true},
+			323: {expr: "popcount(255)", want: int64(8)},
+			324: {expr: "popcount(-1)", want: int64(64)},
+			325: {expr: "clz(1)", want: int64(63)},
+			326: {expr: "clz(0)", want: int64(64)},
+			327: {expr: "ctz(8)", want: int64(3)},
+			328: {expr: "ctz(0)", want: int64(64)},
+			329: {expr: "ctz(1.0)", retErr: true},
+			330: {expr: "gcd(4.0, 6)", want: int64(2)},
+			331: {expr: "lcm(4.0, 6)", want: int64(12)},
+			332: {expr: "gcd(1e300, 2)", retErr: true},
+			333: {expr: "fact(5.0)", want: int64(120)},
+			334: {expr: "fact(-1.0)", retErr: true},
+			335: {expr: "fact(-0.5)", retErr: true},
 			{expr: "fn(0)", retErr: true},
-			{expr: "fn(0)", funcs: map[string]ExprFunc{"fn": fn}, want: 0.0},
-			{expr: "fn()", funcs: map[string]ExprFunc{"fn": fn}, retErr: true},
+			{expr: "fn(0)", funcs: map[string]Func{"fn": fn}, want: 0.0},
+			{expr: "fn()", funcs: map[string]Func{"fn": fn}, retErr: true},
 		}
 
 		for i, test := range tests {
@@ -470,3 +702,138 @@ func TestParse(t *testing.T) {
 		}
 	})
 }
+
+func TestProgram_Eval_limits(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	funcs := map[string]Func{"stop": func([]any) (any, error) { stop(); return int64(1), nil }}
+
+	tests := []struct {
+		expr   string
+		limits Limits
+		want   any
+		err    string
+	}{
+		0:  {expr: "1 + 2*3", limits: Limits{MaxDepth: 3, MaxNodes: 5}, want: int64(7)},
+		1:  {expr: "1 + 2*3", limits: Limits{MaxDepth: 2}, err: "expression depth 3 exceeds limit 2"},
+		2:  {expr: "((1))", limits: Limits{MaxDepth: 2}, err: "expression depth 3 exceeds limit 2"},
+		3:  {expr: "1 + 2*3", limits: Limits{MaxNodes: 4}, err: "expression of 5 nodes exceeds limit 4"},
+		4:  {expr: "max(1, 2, 3)", limits: Limits{MaxNodes: 3}, err: "expression of 4 nodes exceeds limit 3"},
+		5:  {expr: "max(1, abs(-2))", limits: Limits{Funcs: map[string]bool{"max": true, "abs": true}}, want: int64(2)},
+		6:  {expr: "max(1, pow(2, 3))", limits: Limits{Funcs: map[string]bool{"max": true}}, err: `call disallowed function "pow"`},
+		7:  {expr: "abs(-1)", limits: Limits{Funcs: map[string]bool{}}, err: `call disallowed function "abs"`},
+		8:  {expr: "if(true, 1, 2)", limits: Limits{Funcs: map[string]bool{}}, want: int64(1)},
+		9:  {expr: "1", limits: Limits{Context: canceled}, err: "evaluation stopped: context canceled"},
+		10: {expr: "1 + 2", limits: Limits{Context: context.Background()}, want: int64(3)},
+		11: {expr: "stop() + abs(1)", limits: Limits{Context: ctx}, err: "evaluation stopped: context canceled"},
+	}
+	for i, test := range tests {
+		prog, err := Parse(test.expr)
+		if err != nil {
+			t.Fatalf("[%d]ERROR: %v", i, err)
+		}
+		conf := &Config{Funcs: funcs, Limits: test.limits}
+		got, err := prog.Eval(conf)
+		if test.err == "" {
+			if err != nil || got != test.want {
+				t.Errorf("[%d]ERROR: got %v %v, want %v", i, got, err, test.want)
+			}
+			continue
+		}
+		var limitErr *LimitError
+		if !errors.As(err, &limitErr) || limitErr.Error() != test.err {
+			t.Errorf("[%d]ERROR: got error %v, want %s", i, err, test.err)
+		}
+	}
+	prog, err := Parse("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = prog.Eval(&Config{Limits: Limits{Context: canceled}}); !errors.Is(err, context.Canceled) {
+		t.Errorf("ERROR: got error %v, want context.Canceled", err)
+	}
+
+	// nodes of the prefix and the suffix don't count
+	prog, err = ParseWrapped("(iota-1)+(", "2*3", ")")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := &Config{Values: map[string]any{"iota": int64(2)}, Limits: Limits{MaxDepth: 2, MaxNodes: 3}}
+	if got, err := prog.Eval(conf); err != nil || got != int64(7) {
+		t.Errorf("ERROR: got %v %v, want 7", got, err)
+	}
+	conf.Limits.MaxDepth = 1
+	if _, err = prog.Eval(conf); err == nil || err.Error() != `expression depth 2 exceeds limit 1 with expression "2*3"` {
+		t.Errorf("ERROR: got error %v, want depth error", err)
+	}
+}
+
+func TestProgram_Eval_trace(t *testing.T) {
+	prog, err := Parse("(iota-1)+(if(iota > 2, 2.5, -1) * 2)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	conf := &Config{
+		Values: map[string]any{"iota": int64(3)},
+		Trace:  func(expr string, value any) { got = append(got, fmt.Sprintf("%s = %v (%T)", expr, value, value)) },
+	}
+	// the traced closure tree is compiled on the first traced evaluation only
+	untraced := &Config{Values: conf.Values}
+	if _, err = prog.Eval(untraced); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = prog.Eval(conf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = prog.Eval(untraced); err != nil || len(got) != 11 {
+		t.Fatalf("ERROR: got %v and %d steps after untraced evaluation, want 11 steps", err, len(got))
+	}
+	wants := []string{
+		0:  "iota = 3 (int64)",
+		1:  "1 = 1 (int64)",
+		2:  "iota-1 = 2 (int64)",
+		3:  "iota = 3 (int64)",
+		4:  "2 = 2 (int64)",
+		5:  "iota > 2 = true (bool)",
+		6:  "2.5 = 2.5 (float64)",
+		7:  "if(iota > 2, 2.5, -1) = 2.5 (float64)",
+		8:  "2 = 2 (int64)",
+		9:  "if(iota > 2, 2.5, -1) * 2 = 5 (float64)",
+		10: "(iota-1)+(if(iota > 2, 2.5, -1) * 2) = 7 (float64)",
+	}
+	if len(got) != len(wants) {
+		t.Fatalf("ERROR: got %d steps %q, want %d steps", len(got), got, len(wants))
+	}
+	for i, want := range wants {
+		if got[i] != want {
+			t.Errorf("[%d]ERROR: got %s, want %s", i, got[i], want)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse("(iota-3)+(pow(2, iota)*pi+max(1, 2.0, iota))"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProgram_Eval(b *testing.B) {
+	prog, err := Parse("(iota-3)+(pow(2, iota)*pi+max(1, 2.0, iota))")
+	if err != nil {
+		b.Fatal(err)
+	}
+	conf := NewConfig()
+	conf.Values["iota"] = int64(5)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err = prog.Eval(conf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
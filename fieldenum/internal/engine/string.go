@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+func lower(values []any) (any, error) {
+	name := "lower"
+	if err := argNumEq(name, values, 1); err != nil {
+		return nil, err
+	}
+
+	s, ok := values[0].(string)
+	if !ok {
+		return nil, newFuncError(name).setUnsupportedArgType(values)
+	}
+	return strings.ToLower(s), nil
+}
+
+func upper(values []any) (any, error) {
+	name := "upper"
+	if err := argNumEq(name, values, 1); err != nil {
+		return nil, err
+	}
+
+	s, ok := values[0].(string)
+	if !ok {
+		return nil, newFuncError(name).setUnsupportedArgType(values)
+	}
+	return strings.ToUpper(s), nil
+}
+
+func snake(values []any) (any, error) {
+	name := "snake"
+	if err := argNumEq(name, values, 1); err != nil {
+		return nil, err
+	}
+
+	s, ok := values[0].(string)
+	if !ok {
+		return nil, newFuncError(name).setUnsupportedArgType(values)
+	}
+	return joinWords(s, "_"), nil
+}
+
+func kebab(values []any) (any, error) {
+	name := "kebab"
+	if err := argNumEq(name, values, 1); err != nil {
+		return nil, err
+	}
+
+	s, ok := values[0].(string)
+	if !ok {
+		return nil, newFuncError(name).setUnsupportedArgType(values)
+	}
+	return joinWords(s, "-"), nil
+}
+
+func format(values []any) (any, error) {
+	name := "fmt"
+	if err := argNumGe(name, values, 1); err != nil {
+		return nil, err
+	}
+
+	pattern, ok := values[0].(string)
+	if !ok {
+		return nil, newFuncError(name).setUnsupportedArgType(values)
+	}
+	return fmt.Sprintf(pattern, values[1:]...), nil
+}
+
+// joinWords splits s into lower case words and joins them with sep.
+// Words are separated by non-alphanumeric characters and case changes,
+// e.g. "HTTPStatusOK" is split into "http", "status" and "ok".
+func joinWords(s string, sep string) string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return strings.Join(words, sep)
+}
@@ -0,0 +1,35 @@
+package engine
+
+import "math"
+
+var builtinValues = map[string]any{
+	"i":     1i,
+	"e":     math.E,
+	"pi":    math.Pi,
+	"Pi":    math.Pi,
+	"phi":   math.Phi,
+	"Phi":   math.Phi,
+	"nan":   math.NaN(),
+	"NaN":   math.NaN(),
+	"inf":   math.Inf(1),
+	"Inf":   math.Inf(1),
+	"true":  true,
+	"false": false,
+}
+
+// UnitValues are the values of unit identifiers registered on demand,
+// sizes in bytes and durations in nanoseconds like time.Duration.
+var UnitValues = map[string]any{
+	"KiB": int64(1) << 10,
+	"MiB": int64(1) << 20,
+	"GiB": int64(1) << 30,
+	"TiB": int64(1) << 40,
+	"PiB": int64(1) << 50,
+	"ns":  int64(1),
+	"us":  int64(1e3),
+	"µs":  int64(1e3),
+	"ms":  int64(1e6),
+	"s":   int64(1e9),
+	"min": int64(60e9),
+	"h":   int64(3600e9),
+}
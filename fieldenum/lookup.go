@@ -0,0 +1,181 @@
+package fieldenum
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// NameOf returns the name of the first field of enums whose value equals value.
+// A numeric or string value of another type than the field is converted to the field type first,
+// e.g. an untyped constant 1 matches int8 fields and fields of type Color defined as int,
+// but only if the conversion doesn't change the value, so 300 doesn't match int8 fields.
+// Type T must be a struct or struct pointer type accepted by [New].
+// If no field matches or T is invalid, it returns an empty string and false.
+func NameOf[T any](enums T, value any) (string, bool) {
+	v, info, ok := structValue(enums)
+	if !ok {
+		return "", false
+	}
+	for i, name := range info.names {
+		if fv := v.Field(i); fv.Interface() == convertValue(value, fv.Type()) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// convertValue converts numeric and string values to type t if the conversion doesn't change them,
+// otherwise it returns value as is.
+func convertValue(value any, t reflect.Type) any {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || v.Type() == t || !v.Type().ConvertibleTo(t) {
+		return value
+	}
+	kind, tKind := fieldKind(v.Type()), fieldKind(t)
+	numeric := func(kind uint8) bool { return kind >= uintKind && kind <= complexKind }
+	if !(numeric(kind) && numeric(tKind) || kind == stringKind && tKind == stringKind) {
+		return value
+	}
+	converted := v.Convert(t)
+	if !converted.Type().ConvertibleTo(v.Type()) || converted.Convert(v.Type()).Interface() != value {
+		return value
+	}
+	return converted.Interface()
+}
+
+// ValueOf returns the value of the field of enums named name.
+// Type T must be a struct or struct pointer type accepted by [New].
+// If no field is named name or T is invalid, it returns nil and false.
+func ValueOf[T any](enums T, name string) (any, bool) {
+	v, info, ok := structValue(enums)
+	if !ok {
+		return nil, false
+	}
+	i, ok := info.index[name]
+	if !ok {
+		return nil, false
+	}
+	return v.Field(i).Interface(), true
+}
+
+// Names returns the field names of type T in declaration order.
+// Type T must be a struct or struct pointer type accepted by [New], otherwise it returns nil.
+func Names[T any]() []string {
+	info := loadTypeInfo(reflect.TypeOf((*T)(nil)).Elem())
+	if info.err != nil {
+		return nil
+	}
+	return append([]string(nil), info.names...)
+}
+
+// Values returns the field values of enums in declaration order.
+// Type T must be a struct or struct pointer type accepted by [New], otherwise it returns nil.
+//
+// Unlike [Names], Values takes the enums rather than only type T,
+// since values depend on the options of [New] and fields of struct pointers may be modified after assignment.
+func Values[T any](enums T) []any {
+	v, info, ok := structValue(enums)
+	if !ok {
+		return nil
+	}
+	values := make([]any, len(info.names))
+	for i := range values {
+		values[i] = v.Field(i).Interface()
+	}
+	return values
+}
+
+// typeInfo holds the reflection results of a type checked by loadTypeInfo.
+type typeInfo struct {
+	typ    reflect.Type // struct type
+	isPtr  bool
+	kinds  []uint8
+	progs  []fieldProgram
+	groups []*typeInfo // type infos of group fields, nil for other fields
+	names  []string
+	index  map[string]int
+	err    error
+}
+
+// typeInfos caches typeInfo by type.
+var typeInfos sync.Map // map[reflect.Type]*typeInfo
+
+func loadTypeInfo(t reflect.Type) *typeInfo {
+	if info, ok := typeInfos.Load(t); ok {
+		return info.(*typeInfo)
+	}
+	info, _ := typeInfos.LoadOrStore(t, newTypeInfo(t))
+	return info.(*typeInfo)
+}
+
+func newTypeInfo(t reflect.Type) *typeInfo {
+	info := &typeInfo{typ: t}
+	if t.Kind() == reflect.Pointer {
+		info.typ, info.isPtr = t.Elem(), true
+	}
+
+	if info.typ.Kind() != reflect.Struct {
+		info.err = fmt.Errorf(`invalid type "%s"`, t.String())
+		return info
+	}
+
+	info.kinds = make([]uint8, info.typ.NumField())
+	info.groups = make([]*typeInfo, len(info.kinds))
+	info.names = make([]string, len(info.kinds))
+	info.index = make(map[string]int, len(info.kinds))
+	for i := range info.names {
+		field := info.typ.Field(i)
+		tField := field.Type
+		info.names[i] = field.Name
+		info.index[field.Name] = i
+
+		err := newFieldError(field.Name)
+		err.Type = info.typ
+		if !field.IsExported() {
+			info.err = err.setErr(errors.New(`is not settable`))
+			return info
+		}
+		if info.kinds[i] = fieldKind(tField); info.kinds[i] == invalidKind {
+			info.err = err.setErr(fmt.Errorf(`invalid type "%s"`, tField.String()))
+			return info
+		}
+		if info.kinds[i] == groupKind {
+			if info.groups[i] = loadTypeInfo(tField); info.groups[i].err != nil {
+				info.err = groupError(info.groups[i].err, info.typ, field.Name)
+				return info
+			}
+		}
+	}
+	info.progs = compileFields(info.typ, info.kinds)
+	return info
+}
+
+// groupError qualifies the field name of an error of the group field name with the group name,
+// the error of the cached type info of the group is copied.
+func groupError(err error, typ reflect.Type, name string) error {
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		return err
+	}
+	qualified := *fieldErr
+	qualified.Type, qualified.Name = typ, name+"."+fieldErr.Name
+	return &qualified
+}
+
+func structValue[T any](enums T) (reflect.Value, *typeInfo, bool) {
+	info := loadTypeInfo(reflect.TypeOf((*T)(nil)).Elem())
+	if info.err != nil {
+		return reflect.Value{}, nil, false
+	}
+
+	v := reflect.ValueOf(enums)
+	if info.isPtr {
+		if v.IsNil() {
+			return reflect.Value{}, nil, false
+		}
+		v = v.Elem()
+	}
+	return v, info, true
+}
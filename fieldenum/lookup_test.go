@@ -0,0 +1,100 @@
+package fieldenum
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	enums := New[*intEnum]()
+
+	t.Run("NameOf", func(t *testing.T) {
+		tests := []struct {
+			value any
+			want  string
+			ok    bool
+		}{
+			0: {value: 0, want: "A", ok: true},
+			1: {value: 4, want: "D", ok: true},
+			2: {value: 25, want: "F", ok: true},
+			3: {value: 2, ok: false},
+			4: {value: int64(4), want: "D", ok: true},
+			5: {value: 4.0, want: "D", ok: true},
+			6: {value: 4.5, ok: false},
+			7: {value: uint8(16), want: "E", ok: true},
+			8: {value: "4", ok: false},
+			9: {value: nil, ok: false},
+		}
+		for i, test := range tests {
+			if got, ok := NameOf(enums, test.value); got != test.want || ok != test.ok {
+				t.Errorf("[%d]ERROR: got %q %v, want %q %v", i, got, ok, test.want, test.ok)
+			}
+		}
+		if got, ok := NameOf((*intEnum)(nil), 0); ok {
+			t.Errorf("ERROR: got %q, want not found", got)
+		}
+
+		type color int
+		colors := New[struct {
+			Red   color `fieldenum:"1 + iota"`
+			Green color
+			Small int8 `fieldenum:"44"`
+		}]()
+		named := []struct {
+			value any
+			want  string
+			ok    bool
+		}{
+			0: {value: 2, want: "Green", ok: true},
+			1: {value: color(1), want: "Red", ok: true},
+			2: {value: 44, want: "Small", ok: true},
+			3: {value: 300, ok: false},
+		}
+		for i, test := range named {
+			if got, ok := NameOf(colors, test.value); got != test.want || ok != test.ok {
+				t.Errorf("[%d]ERROR: got %q %v, want %q %v", i, got, ok, test.want, test.ok)
+			}
+		}
+	})
+
+	t.Run("ValueOf", func(t *testing.T) {
+		tests := []struct {
+			name string
+			want any
+			ok   bool
+		}{
+			0: {name: "A", want: 0, ok: true},
+			1: {name: "E", want: 16, ok: true},
+			2: {name: "G", ok: false},
+			3: {name: "a", ok: false},
+		}
+		for i, test := range tests {
+			if got, ok := ValueOf(*enums, test.name); got != test.want || ok != test.ok {
+				t.Errorf("[%d]ERROR: got %v %v, want %v %v", i, got, ok, test.want, test.ok)
+			}
+		}
+	})
+
+	t.Run("Names", func(t *testing.T) {
+		want := []string{"A", "B", "C", "D", "E", "F"}
+		if got := Names[intEnum](); !reflect.DeepEqual(got, want) {
+			t.Errorf("ERROR: got %v, want %v", got, want)
+		}
+		if got := Names[*intEnum](); !reflect.DeepEqual(got, want) {
+			t.Errorf("ERROR: got %v, want %v", got, want)
+		}
+		if got := Names[notSettableEnum](); got != nil {
+			t.Errorf("ERROR: got %v, want nil", got)
+		}
+	})
+
+	t.Run("Values", func(t *testing.T) {
+		want := []any{0, 1, 3, 4, 16, 25}
+		if got := Values(enums); !reflect.DeepEqual(got, want) {
+			t.Errorf("ERROR: got %v, want %v", got, want)
+		}
+		if got := Values(0); got != nil {
+			t.Errorf("ERROR: got %v, want nil", got)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package fieldenum
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// sharedKey identifies a value of Shared by the struct type and the fingerprint of options.
+type sharedKey struct {
+	typ         reflect.Type
+	fingerprint string
+}
+
+// sharedValue is a value of Shared computed once.
+type sharedValue struct {
+	once  sync.Once
+	value reflect.Value
+	err   error
+}
+
+// sharedValues caches values of Shared by sharedKey.
+var sharedValues sync.Map // map[sharedKey]*sharedValue
+
+// Shared is like [New] but assigns enum values only once per type T and options,
+// so packages calling Shared for the same type share the evaluation. It's safe for concurrent use.
+//
+// Options are compared by their effects rather than identity, e.g. two WithValues options
+// of equal values are the same. Functions can't be compared, so values assigned with [WithFuncs]
+// or [WithFormatter] aren't cached, and every call evaluates again like [New].
+// The context of [WithContext] is ignored for comparison, and failed evaluations aren't cached.
+//
+// Fields of type [enum.Enum] aren't supported, since the enum names can be registered only once,
+// but values are assigned once per options. It panics for such types, use [New] instead.
+//
+// Shared returns a copy of the cached value, for struct pointer types a pointer to a copy,
+// so modifications of the result don't affect other callers.
+// A struct type and its pointer type share the cached value.
+func Shared[T any](options ...Option) T {
+	v, err := loadShared(reflect.TypeOf((*T)(nil)).Elem(), options)
+	if err != nil {
+		panic(newFieldEnumError(err))
+	}
+	return v.Interface().(T)
+}
+
+// loadShared returns the value of type t cached by the struct type of t,
+// so that struct types and struct pointer types share the value.
+// Values assigned with functions are returned without caching.
+func loadShared(t reflect.Type, options []Option) (reflect.Value, error) {
+	conf, err := configure(options)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	info := loadTypeInfo(t)
+	if info.err != nil {
+		return reflect.Value{}, info.err
+	}
+	if name, ok := info.enumField(); ok {
+		err := newFieldError(name)
+		err.Type = info.typ
+		return reflect.Value{}, err.setErr(errors.New("enum.Enum fields can't be shared, use New instead"))
+	}
+
+	if len(conf.Funcs) > 0 || conf.formatter != nil {
+		ptr := reflect.New(info.typ)
+		if err = assignEnums(conf, ptr.Elem(), info); err != nil {
+			return reflect.Value{}, err
+		}
+		if info.isPtr {
+			return ptr, nil
+		}
+		return ptr.Elem(), nil
+	}
+
+	key := sharedKey{typ: info.typ, fingerprint: conf.fingerprint()}
+	cached, _ := sharedValues.LoadOrStore(key, &sharedValue{})
+	shared := cached.(*sharedValue)
+	shared.once.Do(func() {
+		shared.value = reflect.New(info.typ).Elem()
+		if shared.err = assignEnums(conf, shared.value, info); shared.err != nil {
+			sharedValues.Delete(key)
+		}
+	})
+	if shared.err != nil {
+		return reflect.Value{}, shared.err
+	}
+
+	if !info.isPtr {
+		return shared.value, nil
+	}
+	copied := reflect.New(info.typ)
+	copied.Elem().Set(shared.value)
+	return copied, nil
+}
+
+// enumField returns the qualified name of the first field of type enum.Enum, including fields of groups.
+func (info *typeInfo) enumField() (string, bool) {
+	for i, kind := range info.kinds {
+		switch {
+		case kind == enumKind:
+			return info.names[i], true
+		case kind == groupKind:
+			if name, ok := info.groups[i].enumField(); ok {
+				return info.names[i] + "." + name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// fingerprint identifies the effects of options applied to conf, which has no functions.
+// Names and values are quoted, so that different options can't have the same fingerprint.
+func (conf *config) fingerprint() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "exact=%t lenient=%t stringExpr=%t intFormat=%q floatFormat=%q",
+		conf.Exact, conf.lenient, conf.stringExpr, conf.intFormat, conf.floatFormat)
+	fmt.Fprintf(&builder, " maxDepth=%d maxNodes=%d", conf.Limits.MaxDepth, conf.Limits.MaxNodes)
+	if conf.Limits.Funcs != nil {
+		builder.WriteString(" allowedFuncs=")
+		for _, name := range sortedKeys(conf.Limits.Funcs) {
+			fmt.Fprintf(&builder, "%q,", name)
+		}
+	}
+	for _, name := range sortedKeys(conf.Values) {
+		value := conf.Values[name]
+		fmt.Fprintf(&builder, " value:%q=%T(%q)", name, value, fmt.Sprint(value))
+	}
+	return builder.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
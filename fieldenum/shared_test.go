@@ -0,0 +1,167 @@
+package fieldenum
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/QAQandOwO/godget/enum"
+)
+
+type sharedEnum struct {
+	A int `fieldenum:"base * 10"`
+	B int `fieldenum:"A + 1"`
+	C int `fieldenum:"base + 1"`
+}
+
+// sharedLen returns the number of values cached by Shared.
+func sharedLen() int {
+	n := 0
+	sharedValues.Range(func(_, _ any) bool { n++; return true })
+	return n
+}
+
+func TestShared(t *testing.T) {
+	ResetShared()
+	values := func(base int) Option { return WithValues(map[string]any{"base": base}) }
+
+	want := sharedEnum{A: 10, B: 11, C: 2}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := Shared[sharedEnum](values(1)); got != want {
+				t.Errorf("ERROR: got %v, want %v", got, want)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := sharedLen(); n != 1 {
+		t.Errorf("ERROR: cached %d values, want 1", n)
+	}
+
+	// pointers are copies of the shared value
+	p1, p2 := Shared[*sharedEnum](values(1)), Shared[*sharedEnum](values(1))
+	if p1 == p2 || *p1 != want || *p2 != want {
+		t.Errorf("ERROR: got %p %v and %p %v, want different pointers to equal values", p1, *p1, p2, *p2)
+	}
+	p1.A = 0
+	if p3 := Shared[*sharedEnum](values(1)); p3.A != 10 {
+		t.Errorf("ERROR: got %v, want unmodified value", *p3)
+	}
+	if n := sharedLen(); n != 1 {
+		t.Errorf("ERROR: cached %d values, want 1", n)
+	}
+
+	// different options are evaluated again
+	if got := Shared[sharedEnum](values(2)); got != (sharedEnum{A: 20, B: 21, C: 3}) {
+		t.Errorf("ERROR: got %v, want {20 21 3}", got)
+	}
+	if n := sharedLen(); n != 2 {
+		t.Errorf("ERROR: cached %d values, want 2", n)
+	}
+}
+
+func TestShared_funcs(t *testing.T) {
+	ResetShared()
+	type counted struct {
+		A int `fieldenum:"count() * 10"`
+		B int `fieldenum:"A + 1"`
+	}
+	count := 0
+	funcs := WithFuncs(map[string]ExprFunc{
+		"count": func([]any) (any, error) { count++; return int64(count), nil },
+	})
+
+	// values assigned with functions aren't cached
+	for i := 1; i <= 3; i++ {
+		if got := Shared[counted](funcs); got != (counted{A: i * 10, B: i*10 + 1}) {
+			t.Errorf("[%d]ERROR: got %v, want {%d %d}", i, got, i*10, i*10+1)
+		}
+	}
+	if got := Shared[*counted](funcs); got == nil || got.A != 40 {
+		t.Errorf("ERROR: got %v, want A = 40", got)
+	}
+	formatter := WithFormatter(func(value any) string { return fmt.Sprint(value) })
+	if got := Shared[sharedEnum](WithStringExpr(), formatter, WithValues(map[string]any{"base": 1})); got != (sharedEnum{A: 10, B: 11, C: 2}) {
+		t.Errorf("ERROR: got %v, want {10 11 2}", got)
+	}
+	if n := sharedLen(); n != 0 {
+		t.Errorf("ERROR: cached %d values, want 0", n)
+	}
+}
+
+func TestShared_error(t *testing.T) {
+	ResetShared()
+	for i := 0; i < 2; i++ {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("[%d]ERROR: no panic, want panic", i)
+				}
+			}()
+			Shared[sharedEnum]()
+		}()
+	}
+
+	// errors aren't cached
+	if n := sharedLen(); n != 0 {
+		t.Errorf("ERROR: cached %d values, want 0", n)
+	}
+	if got := Shared[sharedEnum](WithValues(map[string]any{"base": 0})); got.B != 1 {
+		t.Errorf("ERROR: got %v, want B = 1", got)
+	}
+}
+
+type sharedLevel struct{}
+
+func TestShared_enum(t *testing.T) {
+	type levels struct {
+		Group struct {
+			Debug enum.Enum[sharedLevel]
+		}
+	}
+	_, err := loadShared(reflect.TypeOf(levels{}), nil)
+	want := `field "Group.Debug": enum.Enum fields can't be shared, use New instead`
+	if err == nil || err.Error() != want {
+		t.Errorf("ERROR: got error %v, want %s", err, want)
+	}
+	// New still registers the fields
+	if got := New[levels](); got.Group.Debug.Name() != "Debug" {
+		t.Errorf("ERROR: got %v, want Debug", got.Group.Debug)
+	}
+}
+
+func TestConfig_fingerprint(t *testing.T) {
+	tests := []struct {
+		x, y []Option
+		same bool
+	}{
+		0:  {x: nil, y: []Option{WithValues(nil)}, same: true},
+		1:  {x: []Option{WithValues(map[string]any{"a": 1, "b": 2})}, y: []Option{WithValues(map[string]any{"b": 2}), WithValues(map[string]any{"a": 1})}, same: true},
+		2:  {x: []Option{WithValues(map[string]any{"a": 1})}, y: []Option{WithValues(map[string]any{"a": int8(1)})}, same: false},
+		3:  {x: []Option{WithValues(map[string]any{"a": 1})}, y: []Option{WithValues(map[string]any{"a": "1"})}, same: false},
+		4:  {x: []Option{WithFloatFormat("%g")}, y: []Option{WithFloatFormat("%e")}, same: false},
+		5:  {x: []Option{WithAllowedFuncs()}, y: nil, same: false},
+		6:  {x: []Option{WithExactArithmetic(), WithMaxDepth(3)}, y: []Option{WithMaxDepth(3), WithExactArithmetic()}, same: true},
+		7:  {x: []Option{WithIntFormat("%x")}, y: []Option{WithIntFormat("%X")}, same: false},
+		8:  {x: []Option{WithUnits()}, y: nil, same: false},
+		9:  {x: []Option{WithValues(map[string]any{"a": "x) value:b=int(2"})}, y: []Option{WithValues(map[string]any{"a": "x", "b": 2})}, same: false},
+		10: {x: []Option{WithAllowedFuncs("a,b")}, y: []Option{WithAllowedFuncs("a", "b")}, same: false},
+	}
+	for i, test := range tests {
+		x, err := configure(test.x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y, err := configure(test.y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if same := x.fingerprint() == y.fingerprint(); same != test.same {
+			t.Errorf("[%d]ERROR: got same %v, want %v: %s, %s", i, same, test.same, x.fingerprint(), y.fingerprint())
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package fieldenum
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tagOptions are the options of a fieldenum struct tag.
+// Offsets are the byte offsets of option values in the tag.
+type tagOptions struct {
+	expr       string // expression, or verbatim value of string fields
+	hasExpr    bool
+	exprOffset int
+	base       string // base expression of group fields
+	hasBase    bool
+	baseOffset int
+	step       int64 // 0 if not set
+	skip       bool  // the field doesn't count for iota and its expression isn't inherited
+	reset      bool  // iota restarts at 0 from the field
+	ignore     bool  // the field is left untouched
+}
+
+// parseTag parses a fieldenum struct tag of a non-string field. A tag is "-", or comma-separated options
+// if it starts with an option, otherwise the whole tag is the expression:
+//
+//	expr=<expression>,base=<expression>,step=<integer>,skip,reset
+func parseTag(tag string) (tagOptions, error) {
+	if tag == "-" {
+		return tagOptions{ignore: true}, nil
+	}
+	items := splitTag(tag)
+	if _, ok := tagOption(items[0].text); !ok {
+		return tagOptions{expr: tag, hasExpr: true}, nil
+	}
+
+	var opts tagOptions
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		key, ok := tagOption(item.text)
+		if !ok {
+			return opts, fmt.Errorf(`invalid tag option "%s"`, strings.TrimSpace(item.text))
+		}
+		if seen[key] {
+			return opts, fmt.Errorf(`duplicate tag option "%s"`, key)
+		}
+		seen[key] = true
+
+		value := strings.TrimLeft(item.text, " \t")[len(key):]
+		offset := item.offset + len(item.text) - len(value)
+		if strings.HasPrefix(value, "=") {
+			value, offset = value[1:], offset+1
+		}
+		switch key {
+		case "expr":
+			opts.expr, opts.hasExpr, opts.exprOffset = value, true, offset
+		case "base":
+			opts.base, opts.hasBase, opts.baseOffset = value, true, offset
+		case "step":
+			step, err := strconv.ParseInt(strings.TrimSpace(value), 0, 64)
+			if err != nil || step == 0 {
+				return opts, fmt.Errorf(`invalid tag option "step=%s", want a non-zero integer`, value)
+			}
+			opts.step = step
+		case "skip":
+			opts.skip = true
+		case "reset":
+			opts.reset = true
+		}
+	}
+	return opts, nil
+}
+
+// tagOption returns the key of the option item, or false if the item isn't an option.
+func tagOption(item string) (string, bool) {
+	item = strings.TrimSpace(item)
+	switch item {
+	case "skip", "reset":
+		return item, true
+	}
+	for _, key := range []string{"expr", "base", "step"} {
+		// "expr==x" is a comparison rather than an option
+		if strings.HasPrefix(item, key+"=") && !strings.HasPrefix(item, key+"==") {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+type tagItem struct {
+	text   string
+	offset int
+}
+
+// splitTag splits the tag by commas outside of parentheses, brackets and quoted strings.
+func splitTag(tag string) []tagItem {
+	var (
+		items []tagItem
+		depth int
+		quote byte
+		start int
+	)
+	for i := 0; i < len(tag); i++ {
+		c := tag[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, tagItem{text: tag[start:i], offset: start})
+			start = i + 1
+		}
+	}
+	return append(items, tagItem{text: tag[start:], offset: start})
+}
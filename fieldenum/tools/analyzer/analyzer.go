@@ -0,0 +1,31 @@
+// Package analyzer provides an analyzer which validates fieldenum struct tags at compile time.
+//
+// The analyzer finds instantiations of fieldenum.New, fieldenum.TryNew, fieldenum.Shared and fieldenum.Explain,
+// and evaluates the struct tags of their type arguments with the same parser and built-in tables
+// as package fieldenum. Errors which would panic at program startup, such as unsupported identifiers,
+// wrong argument counts of built-in functions, unexported fields and invalid field types,
+// are reported with positions on the struct tags.
+//
+// It can be run by go vet with a vet tool built on [golang.org/x/tools/go/analysis/singlechecker],
+// or added to any driver of [golang.org/x/tools/go/analysis].
+package analyzer
+
+import (
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/QAQandOwO/godget/fieldenum/tools/internal/check"
+)
+
+// Analyzer validates fieldenum struct tags.
+var Analyzer = &analysis.Analyzer{
+	Name: "fieldenum",
+	Doc:  "check fieldenum struct tags of fieldenum.New, TryNew, Shared and Explain type arguments",
+	Run:  run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	for _, diag := range check.Files(pass.Files, pass.TypesInfo) {
+		pass.Report(analysis.Diagnostic{Pos: diag.Pos, End: diag.End, Message: diag.Message})
+	}
+	return nil, nil
+}
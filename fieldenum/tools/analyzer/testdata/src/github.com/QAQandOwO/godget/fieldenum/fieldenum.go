@@ -0,0 +1,16 @@
+// Package fieldenum is a stub of package fieldenum for type-checking test packages.
+package fieldenum
+
+type Option func()
+
+func New[T any](options ...Option) T { var zero T; return zero }
+
+func TryNew[T any](options ...Option) (T, error) { var zero T; return zero, nil }
+
+func WithValues(values map[string]any) Option { return nil }
+
+func Shared[T any](options ...Option) T { var zero T; return zero }
+
+type Explanation struct{}
+
+func Explain[T any](options ...Option) ([]Explanation, error) { return nil, nil }
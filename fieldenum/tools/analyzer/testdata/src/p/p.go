@@ -0,0 +1,25 @@
+package p
+
+import "github.com/QAQandOwO/godget/fieldenum"
+
+type Flags struct {
+	A int `fieldenum:"1 << iota"`
+	B int `fieldenum:"pow(2)"` // want `fieldenum: Flags.B: call function pow on too few arguments`
+	C int `fieldenum:"C0 + 1"` // want `fieldenum: Flags.C: unsupported identifier "C0"`
+}
+
+var _ = fieldenum.New[Flags]()
+
+// registered values are unknown, so only syntax errors and argument counts are reported
+var _, _ = fieldenum.TryNew[struct {
+	X int `fieldenum:"max() + x"` // want `fieldenum: struct\{...\}.X: call function max on too few arguments`
+	Y int `fieldenum:"y"`
+}](fieldenum.WithValues(nil))
+
+var _ = fieldenum.Shared[struct {
+	S int `fieldenum:"pow(1, 2, 3)"` // want `fieldenum: struct\{...\}.S: call function pow on too many arguments`
+}]()
+
+var _, _ = fieldenum.Explain[struct {
+	E int8 `fieldenum:"1 << 8"` // want `fieldenum: struct\{...\}.E: value "256" overflows type int8`
+}]()
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/QAQandOwO/godget/fieldenum"
+	"github.com/QAQandOwO/godget/fieldenum/tools/internal/check"
+	"github.com/QAQandOwO/godget/fieldenum/tools/internal/load"
+)
+
+// options are the fieldenum options used for evaluation and in generated tests.
+type options struct {
+	exact       bool
+	lenient     bool
+	stringExpr  bool
+	units       bool
+	intFormat   string
+	floatFormat string
+}
+
+func (o options) fieldenumOptions() []fieldenum.Option {
+	var opts []fieldenum.Option
+	if o.exact {
+		opts = append(opts, fieldenum.WithExactArithmetic())
+	}
+	if o.lenient {
+		opts = append(opts, fieldenum.WithLenientAssignment())
+	}
+	if o.stringExpr {
+		opts = append(opts, fieldenum.WithStringExpr())
+	}
+	if o.units {
+		opts = append(opts, fieldenum.WithUnits())
+	}
+	if o.intFormat != "" {
+		opts = append(opts, fieldenum.WithIntFormat(o.intFormat))
+	}
+	if o.floatFormat != "" {
+		opts = append(opts, fieldenum.WithFloatFormat(o.floatFormat))
+	}
+	return opts
+}
+
+// flags returns the options as command line flags.
+func (o options) flags() []string {
+	var flags []string
+	if o.exact {
+		flags = append(flags, "-exact")
+	}
+	if o.lenient {
+		flags = append(flags, "-lenient")
+	}
+	if o.stringExpr {
+		flags = append(flags, "-stringexpr")
+	}
+	if o.units {
+		flags = append(flags, "-units")
+	}
+	if o.intFormat != "" {
+		flags = append(flags, "-intformat="+strconv.Quote(o.intFormat))
+	}
+	if o.floatFormat != "" {
+		flags = append(flags, "-floatformat="+strconv.Quote(o.floatFormat))
+	}
+	return flags
+}
+
+// source returns the options as Go source, used as arguments of fieldenum.TryNew.
+func (o options) source() string {
+	var opts []string
+	if o.exact {
+		opts = append(opts, "fieldenum.WithExactArithmetic()")
+	}
+	if o.lenient {
+		opts = append(opts, "fieldenum.WithLenientAssignment()")
+	}
+	if o.stringExpr {
+		opts = append(opts, "fieldenum.WithStringExpr()")
+	}
+	if o.units {
+		opts = append(opts, "fieldenum.WithUnits()")
+	}
+	if o.intFormat != "" {
+		opts = append(opts, "fieldenum.WithIntFormat("+strconv.Quote(o.intFormat)+")")
+	}
+	if o.floatFormat != "" {
+		opts = append(opts, "fieldenum.WithFloatFormat("+strconv.Quote(o.floatFormat)+")")
+	}
+	return strings.Join(opts, ", ")
+}
+
+type generator struct {
+	types   []string
+	output  string // base name of the output file, which is ignored when loading the package
+	test    bool   // a test file is written too
+	options options
+}
+
+// command returns the command line reproducing the generated files, written in their headers.
+// The output file name is included if it isn't the default name.
+func (g *generator) command() string {
+	args := []string{"fieldenumgen", "-type=" + strings.Join(g.types, ",")}
+	if g.output != defaultOutput(g.types) {
+		args = append(args, "-output="+g.output)
+	}
+	if g.test {
+		args = append(args, "-test")
+	}
+	return strings.Join(append(args, g.options.flags()...), " ")
+}
+
+// defaultOutput returns the default base name of the output file of the types.
+func defaultOutput(types []string) string {
+	return strings.ToLower(types[0]) + "_fieldenum.go"
+}
+
+// constant is a generated constant of a field.
+type constant struct {
+	name  string // constant name
+	field string // field name
+	typ   types.Type
+	value string // Go source of the value
+}
+
+// generate returns the generated source and the source of the generated test of the package in dir.
+func (g *generator) generate(dir string) ([]byte, []byte, error) {
+	pkg, err := load.Dir(token.NewFileSet(), dir, g.output)
+	if err != nil {
+		return nil, nil, err
+	}
+	// packages of the qualified type names in the generated constants, by import path
+	imports := make(map[string]*types.Package)
+	qualifier := func(other *types.Package) string {
+		if other == pkg.Types {
+			return ""
+		}
+		imports[other.Path()] = other
+		return other.Name()
+	}
+
+	var (
+		body, testBuf bytes.Buffer
+		stringers     []*types.Named
+		cases         = make(map[*types.Named][]constant)
+	)
+	header := fmt.Sprintf("// Code generated by %s; DO NOT EDIT.\n\npackage %s\n\n", g.command(), pkg.Types.Name())
+	testBuf.WriteString(header)
+	testBuf.WriteString("import (\n\t\"testing\"\n\n\t\"github.com/QAQandOwO/godget/fieldenum\"\n)\n")
+
+	for _, name := range g.types {
+		consts, err := g.constants(pkg.Types, name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		fmt.Fprintf(&body, "// Constants of the fields of %s, generated from their fieldenum struct tags.\nconst (\n", name)
+		for _, c := range consts {
+			fmt.Fprintf(&body, "\t%s %s = %s\n", c.name, types.TypeString(c.typ, qualifier), c.value)
+
+			named, ok := c.typ.(*types.Named)
+			if !ok || !isStringer(pkg.Types, named) {
+				continue
+			}
+			if _, seen := cases[named]; !seen {
+				stringers = append(stringers, named)
+			}
+			cases[named] = append(cases[named], c)
+		}
+		body.WriteString(")\n\n")
+
+		fmt.Fprintf(&testBuf, "\nfunc Test%sFieldenum(t *testing.T) {\n", name)
+		fmt.Fprintf(&testBuf, "\tgot, err := fieldenum.TryNew[%s](%s)\n", name, g.options.source())
+		testBuf.WriteString("\tif err != nil {\n\t\tt.Fatal(err)\n\t}\n")
+		fmt.Fprintf(&testBuf, "\twant := %s{\n", name)
+		for _, c := range consts {
+			fmt.Fprintf(&testBuf, "\t\t%s: %s,\n", c.field, c.name)
+		}
+		testBuf.WriteString("\t}\n\tif got != want {\n")
+		fmt.Fprintf(&testBuf, "\t\tt.Errorf(\"fieldenum.New[%s]() = %%+v, generated constants are %%+v, run go generate\", got, want)\n", name)
+		testBuf.WriteString("\t}\n}\n")
+	}
+
+	if len(stringers) > 0 {
+		imports["strconv"] = types.NewPackage("strconv", "strconv")
+	}
+	importSrc, err := importDecl(imports)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	buf.WriteString(importSrc)
+	buf.Write(body.Bytes())
+	for _, named := range stringers {
+		writeStringer(&buf, named, cases[named], qualifier)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	testSrc, err := format.Source(testBuf.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	return src, testSrc, nil
+}
+
+// constants evaluates the fields of the struct type named name.
+func (g *generator) constants(pkg *types.Package, name string) ([]constant, error) {
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not declared in package %s", name, pkg.Name())
+	}
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a struct type", name)
+	}
+
+	fields := make([]reflect.StructField, st.NumFields())
+	for i := range fields {
+		v := st.Field(i)
+		switch rt := check.ReflectType(v.Type()); {
+		case !v.Exported():
+			return nil, fmt.Errorf("%s.%s: is not settable", name, v.Name())
+		case check.IsEnum(v.Type()):
+			return nil, fmt.Errorf("%s.%s: enum.Enum fields can't be generated as constants", name, v.Name())
+		case isComposite(v.Type()):
+			return nil, fmt.Errorf("%s.%s: group and array fields can't be generated as constants", name, v.Name())
+		case rt == nil:
+			return nil, fmt.Errorf(`%s.%s: invalid type "%s"`, name, v.Name(), v.Type().String())
+		default:
+			fields[i] = reflect.StructField{Name: v.Name(), Type: rt, Tag: reflect.StructTag(st.Tag(i))}
+		}
+	}
+
+	rv, err := fieldenum.NewValue(reflect.StructOf(fields), g.options.fieldenumOptions()...)
+	if err != nil {
+		var errs fieldenum.Errors
+		if !errors.As(err, &errs) {
+			return nil, err
+		}
+		msgs := make([]string, len(errs))
+		for i, err := range errs {
+			msgs[i] = err.Error()
+			var fieldErr *fieldenum.FieldError
+			if errors.As(err, &fieldErr) {
+				fieldErr.Type = nil
+				msgs[i] = name + "." + fieldErr.Diagnostic()
+			}
+		}
+		return nil, errors.New(strings.Join(msgs, "\n"))
+	}
+
+	consts := make([]constant, 0, len(fields))
+	for i, field := range fields {
+		// fields ignored by the tag "-" are left untouched and have no constants
+		if field.Tag.Get("fieldenum") == "-" {
+			continue
+		}
+		value, err := literal(rv.Field(i))
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", name, field.Name, err)
+		}
+		consts = append(consts, constant{name: name + field.Name, field: field.Name, typ: st.Field(i).Type(), value: value})
+	}
+	return consts, nil
+}
+
+// importDecl returns the import declaration of the packages by import path,
+// with standard library packages grouped first.
+func importDecl(imports map[string]*types.Package) (string, error) {
+	if len(imports) == 0 {
+		return "", nil
+	}
+	var std, other []string
+	names := make(map[string]string)
+	for path, p := range imports {
+		if seen, ok := names[p.Name()]; ok {
+			return "", fmt.Errorf("packages %s and %s have the same name %s", seen, path, p.Name())
+		}
+		names[p.Name()] = path
+		if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+
+	var b strings.Builder
+	b.WriteString("import (\n")
+	for _, path := range std {
+		fmt.Fprintf(&b, "\t%q\n", path)
+	}
+	if len(std) > 0 && len(other) > 0 {
+		b.WriteString("\n")
+	}
+	for _, path := range other {
+		fmt.Fprintf(&b, "\t%q\n", path)
+	}
+	b.WriteString(")\n\n")
+	return b.String(), nil
+}
+
+// isComposite reports whether t is a group or an array type, which has no constants.
+func isComposite(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Struct, *types.Array:
+		return true
+	}
+	return false
+}
+
+// literal returns the Go source of a constant of value v.
+func literal(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return floatLiteral(v.Float(), v.Type().Bits())
+	case reflect.Complex64, reflect.Complex128:
+		c, bits := v.Complex(), v.Type().Bits()/2
+		re, err := floatLiteral(real(c), bits)
+		if err != nil {
+			return "", err
+		}
+		im, err := floatLiteral(imag(c), bits)
+		if err != nil {
+			return "", err
+		}
+		return "complex(" + re + ", " + im + ")", nil
+	case reflect.String:
+		return strconv.Quote(v.String()), nil
+	}
+	return "", fmt.Errorf(`invalid type "%s"`, v.Type().String())
+}
+
+func floatLiteral(f float64, bits int) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf(`value "%v" can't be a constant`, f)
+	}
+	return strconv.FormatFloat(f, 'g', -1, bits), nil
+}
+
+// isStringer reports whether a String method can be generated for the named type,
+// i.e. it's an integer type declared in pkg without String method.
+func isStringer(pkg *types.Package, named *types.Named) bool {
+	basic, ok := named.Underlying().(*types.Basic)
+	if !ok || basic.Info()&types.IsInteger == 0 || named.Obj().Pkg() != pkg {
+		return false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(named, true, pkg, "String")
+	return obj == nil
+}
+
+// writeStringer writes the String method of the named type, returning the field name of the value.
+// If fields have the same value, the first field is used.
+func writeStringer(buf *bytes.Buffer, named *types.Named, consts []constant, qualifier types.Qualifier) {
+	typ := types.TypeString(named, qualifier)
+	fmt.Fprintf(buf, "// String returns the field name of value x.\nfunc (x %s) String() string {\n\tswitch x {\n", typ)
+	seen := make(map[string]bool)
+	for _, c := range consts {
+		if seen[c.value] {
+			continue
+		}
+		seen[c.value] = true
+		fmt.Fprintf(buf, "\tcase %s:\n\t\treturn %q\n", c.name, c.field)
+	}
+	buf.WriteString("\t}\n")
+
+	basic := named.Underlying().(*types.Basic)
+	if basic.Info()&types.IsUnsigned != 0 {
+		fmt.Fprintf(buf, "\treturn \"%s(\" + strconv.FormatUint(uint64(x), 10) + \")\"\n}\n", typ)
+	} else {
+		fmt.Fprintf(buf, "\treturn \"%s(\" + strconv.FormatInt(int64(x), 10) + \")\"\n}\n", typ)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QAQandOwO/godget/fieldenum/tools/internal/load"
+)
+
+// TestGenerate checks that the files generated in testdata/status are up to date.
+// The generated test in testdata/status checks that they agree with fieldenum.New.
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join("testdata", "status")
+	g := &generator{types: []string{"Status", "Limit"}, output: "status_fieldenum.go", test: true, options: options{units: true}}
+	src, testSrc, err := g.generate(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, got := range map[string][]byte{"status_fieldenum.go": src, "status_fieldenum_test.go": testSrc} {
+		want, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("ERROR: %s is stale, got:\n%s", name, got)
+		}
+	}
+}
+
+// TestGenerate_test runs the generated test in testdata/status,
+// so that the generated constants are checked against fieldenum.New.
+func TestGenerate_test(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go test of testdata in short mode")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	out, err := exec.Command(goCmd, "test", "./testdata/status").CombinedOutput()
+	if err != nil {
+		t.Errorf("ERROR: %v\n%s", err, out)
+	}
+}
+
+func TestGenerate_error(t *testing.T) {
+	tests := []struct {
+		types []string
+		want  string
+	}{
+		0: {types: []string{"Missing"}, want: "type Missing is not declared"},
+		1: {types: []string{"Code"}, want: "type Code is not a struct type"},
+		2: {types: []string{"Levels"}, want: "Levels.Debug: enum.Enum fields can't be generated as constants"},
+		3: {types: []string{"Invalid"}, want: `Invalid.B: unsupported identifier "x"`},
+		4: {types: []string{"Grouped"}, want: "Grouped.A: group and array fields can't be generated as constants"},
+	}
+	for i, test := range tests {
+		g := &generator{types: test.types}
+		if _, _, err := g.generate(filepath.Join("testdata", "invalid")); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("[%d]ERROR: got %v, want error containing %s", i, err, test.want)
+		}
+	}
+}
+
+// TestGenerate_sharedType generates struct types sharing a field type separately,
+// the String method is written by the first generated file only.
+func TestGenerate_sharedType(t *testing.T) {
+	dir := t.TempDir()
+	src, err := os.ReadFile(filepath.Join("testdata", "shared", "shared.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "shared.go"), src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stringers int
+	for _, name := range []string{"Success", "Failure"} {
+		g := &generator{types: []string{name}, output: defaultOutput([]string{name})}
+		got, _, err := g.generate(dir)
+		if err != nil {
+			t.Fatalf("ERROR: %s: %v", name, err)
+		}
+		if strings.Contains(string(got), "func (x Code) String() string") {
+			stringers++
+		}
+		if err = os.WriteFile(filepath.Join(dir, g.output), got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stringers != 1 {
+		t.Errorf("ERROR: got %d String methods of Code, want 1", stringers)
+	}
+	if _, err = load.Dir(token.NewFileSet(), dir); err != nil {
+		t.Errorf("ERROR: generated package doesn't type-check: %v", err)
+	}
+}
+
+func TestGenerator_command(t *testing.T) {
+	tests := []struct {
+		g    generator
+		want string
+	}{
+		0: {g: generator{types: []string{"Status"}, output: "status_fieldenum.go"}, want: "fieldenumgen -type=Status"},
+		1: {
+			g:    generator{types: []string{"Status", "Limit"}, output: "limits.go", test: true, options: options{exact: true, intFormat: "%x"}},
+			want: `fieldenumgen -type=Status,Limit -output=limits.go -test -exact -intformat="%x"`,
+		},
+	}
+	for i, test := range tests {
+		if got := test.g.command(); got != test.want {
+			t.Errorf("[%d]ERROR: got %s, want %s", i, got, test.want)
+		}
+	}
+}
+
+func TestOptions_source(t *testing.T) {
+	o := options{stringExpr: true, units: true, intFormat: "0x%04X", floatFormat: "%.3g"}
+	want := `fieldenum.WithStringExpr(), fieldenum.WithUnits(), fieldenum.WithIntFormat("0x%04X"), fieldenum.WithFloatFormat("%.3g")`
+	if got := o.source(); got != want {
+		t.Errorf("ERROR: got %s, want %s", got, want)
+	}
+	if got := len(o.fieldenumOptions()); got != 4 {
+		t.Errorf("ERROR: got %d options, want 4", got)
+	}
+}
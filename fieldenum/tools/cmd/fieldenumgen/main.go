@@ -0,0 +1,117 @@
+// Command fieldenumgen generates Go constants from fieldenum struct tags,
+// for code which must not evaluate constants by reflection at runtime.
+//
+// For each struct type, the fields are evaluated with the same evaluator as fieldenum.New,
+// and a typed const block is written, named by the struct type and the field name.
+// For named integer field types declared in the package without String method,
+// a String method returning the field name is written like stringer.
+//
+// Usage:
+//
+//	fieldenumgen -type=Status[,Type...] [flags] [directory]
+//
+// For example, with a go:generate directive next to the struct type:
+//
+//	//go:generate go run github.com/QAQandOwO/godget/fieldenum/tools/cmd/fieldenumgen@latest -type=Status -test
+//	type Code int
+//
+//	type Status struct {
+//		OK       Code `fieldenum:"200"`
+//		Created  Code
+//		NotFound Code `fieldenum:"404"`
+//	}
+//
+// the generated file status_fieldenum.go contains:
+//
+//	const (
+//		StatusOK       Code = 200
+//		StatusCreated  Code = 201
+//		StatusNotFound Code = 404
+//	)
+//
+//	func (x Code) String() string { ... }
+//
+// With -test, a test file is written too, which checks that fieldenum.New
+// and the generated constants agree, so that stale generated code fails tests.
+//
+// If a named type already has a String method, e.g. written by the generated file of another struct type
+// sharing the field type, the String method isn't written again.
+// The header of generated files records the command line, so that they can be reproduced.
+//
+// Fields of type enum.Enum, group fields and array fields can't be constants and aren't supported.
+// Types of other packages, like time.Duration, are imported by the generated file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	typeNames   = flag.String("type", "", "comma-separated list of struct type names; required")
+	output      = flag.String("output", "", "output file name; default <type>_fieldenum.go in the directory")
+	genTest     = flag.Bool("test", false, "also write a test checking that fieldenum.New agrees with the generated constants")
+	exact       = flag.Bool("exact", false, "evaluate with fieldenum.WithExactArithmetic")
+	lenient     = flag.Bool("lenient", false, "evaluate with fieldenum.WithLenientAssignment")
+	stringExpr  = flag.Bool("stringexpr", false, "evaluate with fieldenum.WithStringExpr")
+	units       = flag.Bool("units", false, "evaluate with fieldenum.WithUnits")
+	intFormat   = flag.String("intformat", "", "evaluate with fieldenum.WithIntFormat of the format")
+	floatFormat = flag.String("floatformat", "", "evaluate with fieldenum.WithFloatFormat of the format")
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: fieldenumgen -type=Status[,Type...] [flags] [directory]")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if *typeNames == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+	g := &generator{
+		types: strings.Split(*typeNames, ","),
+		test:  *genTest,
+		options: options{
+			exact:       *exact,
+			lenient:     *lenient,
+			stringExpr:  *stringExpr,
+			units:       *units,
+			intFormat:   *intFormat,
+			floatFormat: *floatFormat,
+		},
+	}
+
+	name := *output
+	if name == "" {
+		name = filepath.Join(dir, defaultOutput(g.types))
+	}
+	g.output = filepath.Base(name)
+
+	src, testSrc, err := g.generate(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fieldenumgen:", err)
+		os.Exit(1)
+	}
+	if err = os.WriteFile(name, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "fieldenumgen:", err)
+		os.Exit(1)
+	}
+	if g.test {
+		testName := strings.TrimSuffix(name, ".go") + "_test.go"
+		if err = os.WriteFile(testName, testSrc, 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, "fieldenumgen:", err)
+			os.Exit(1)
+		}
+	}
+}
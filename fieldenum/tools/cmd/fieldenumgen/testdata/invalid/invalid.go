@@ -0,0 +1,24 @@
+// Package invalid holds types which can't be generated by fieldenumgen.
+package invalid
+
+import "github.com/QAQandOwO/godget/enum"
+
+type Code int
+
+type level struct{}
+
+type Levels struct {
+	Debug enum.Enum[level]
+}
+
+type Invalid struct {
+	A int
+	B int `fieldenum:"x"`
+}
+
+type Grouped struct {
+	A struct {
+		B int
+	}
+	C [2]int
+}
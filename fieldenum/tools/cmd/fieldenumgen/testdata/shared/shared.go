@@ -0,0 +1,14 @@
+// Package shared holds struct types sharing a field type, which are generated separately.
+package shared
+
+type Code int
+
+type Success struct {
+	OK      Code `fieldenum:"200"`
+	Created Code
+}
+
+type Failure struct {
+	NotFound Code `fieldenum:"404"`
+	Teapot   Code `fieldenum:"418"`
+}
@@ -0,0 +1,28 @@
+// Package status is an example package for fieldenumgen.
+package status
+
+import "time"
+
+//go:generate go run github.com/QAQandOwO/godget/fieldenum/tools/cmd/fieldenumgen -type=Status,Limit -test -units
+
+// Code is an HTTP status code.
+type Code int
+
+// Status holds HTTP status codes.
+type Status struct {
+	OK        Code `fieldenum:"200"`
+	Created   Code
+	Accepted  Code
+	NotFound  Code `fieldenum:"404"`
+	Teapot    Code `fieldenum:"NotFound + 14"`
+	Forbidden Code `fieldenum:"403"`
+}
+
+// Limit holds limits of different types.
+type Limit struct {
+	MaxSize  uint64        `fieldenum:"1 << 20"`
+	Ratio    float32       `fieldenum:"0.25"`
+	Label    string        `fieldenum:"limit"`
+	Rotation complex128    `fieldenum:"1 + 2i"`
+	Timeout  time.Duration `fieldenum:"30*s"`
+}
@@ -0,0 +1,46 @@
+// Code generated by fieldenumgen -type=Status,Limit -test -units; DO NOT EDIT.
+
+package status
+
+import (
+	"strconv"
+	"time"
+)
+
+// Constants of the fields of Status, generated from their fieldenum struct tags.
+const (
+	StatusOK        Code = 200
+	StatusCreated   Code = 201
+	StatusAccepted  Code = 202
+	StatusNotFound  Code = 404
+	StatusTeapot    Code = 418
+	StatusForbidden Code = 403
+)
+
+// Constants of the fields of Limit, generated from their fieldenum struct tags.
+const (
+	LimitMaxSize  uint64        = 1048576
+	LimitRatio    float32       = 0.25
+	LimitLabel    string        = "limit"
+	LimitRotation complex128    = complex(1, 2)
+	LimitTimeout  time.Duration = 30000000000
+)
+
+// String returns the field name of value x.
+func (x Code) String() string {
+	switch x {
+	case StatusOK:
+		return "OK"
+	case StatusCreated:
+		return "Created"
+	case StatusAccepted:
+		return "Accepted"
+	case StatusNotFound:
+		return "NotFound"
+	case StatusTeapot:
+		return "Teapot"
+	case StatusForbidden:
+		return "Forbidden"
+	}
+	return "Code(" + strconv.FormatInt(int64(x), 10) + ")"
+}
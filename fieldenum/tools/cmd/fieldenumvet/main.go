@@ -0,0 +1,110 @@
+// Command fieldenumvet reports invalid fieldenum struct tags at build time.
+//
+// It loads packages, finds instantiations of fieldenum.New, fieldenum.TryNew, fieldenum.Shared and fieldenum.Explain,
+// and checks the struct tags of their type arguments with the same evaluator as package fieldenum,
+// so that invalid tags are reported like go vet instead of panicking at program startup.
+//
+// Usage:
+//
+//	fieldenumvet [packages]
+//
+// Packages are directories, and a directory followed by "/..." includes its subdirectories.
+// The default is the current directory. Diagnostics are printed as:
+//
+//	levels.go:8:34: fieldenum: Levels.Warn: call non-existed function "foo"
+//		1 + foo(2)
+//		    ^~~~~~
+//
+// The exit status is 1 if any diagnostic is reported, or 2 if packages can't be loaded.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/build"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/QAQandOwO/godget/fieldenum/tools/internal/check"
+	"github.com/QAQandOwO/godget/fieldenum/tools/internal/load"
+)
+
+func main() {
+	patterns := os.Args[1:]
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	dirs, err := expand(patterns)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fieldenumvet:", err)
+		os.Exit(2)
+	}
+
+	status := 0
+	fset := token.NewFileSet()
+	for _, dir := range dirs {
+		diags, err := vetDir(fset, dir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "fieldenumvet:", err)
+			status = 2
+			continue
+		}
+		for _, diag := range diags {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", fset.Position(diag.Pos), diag.Message)
+			if diag.Snippet != "" {
+				fmt.Fprintln(os.Stderr, diag.Snippet)
+			}
+			if status == 0 {
+				status = 1
+			}
+		}
+	}
+	os.Exit(status)
+}
+
+// expand returns the directories matched by patterns.
+func expand(patterns []string) ([]string, error) {
+	var dirs []string
+	for _, pattern := range patterns {
+		if !strings.HasSuffix(pattern, "/...") {
+			dirs = append(dirs, pattern)
+			continue
+		}
+		root := strings.TrimSuffix(pattern, "/...")
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if name := d.Name(); d.IsDir() && path != root &&
+				(name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			if d.IsDir() {
+				dirs = append(dirs, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dirs, nil
+}
+
+// vetDir type-checks the package in dir and checks its fieldenum struct tags.
+// Directories without Go files are skipped.
+func vetDir(fset *token.FileSet, dir string) ([]check.Diagnostic, error) {
+	pkg, err := load.Dir(fset, dir)
+	if err != nil {
+		var noGoErr *build.NoGoError
+		if errors.As(err, &noGoErr) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return check.Files(pkg.Files, pkg.Info), nil
+}
@@ -0,0 +1,13 @@
+module github.com/QAQandOwO/godget/fieldenum/tools
+
+go 1.18
+
+require (
+	github.com/QAQandOwO/godget v0.0.0-20261018215603-fad5acb54376
+	golang.org/x/tools v0.6.0
+)
+
+require (
+	golang.org/x/mod v0.8.0 // indirect
+	golang.org/x/sys v0.5.0 // indirect
+)
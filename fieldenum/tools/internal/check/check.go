@@ -0,0 +1,346 @@
+// Package check finds instantiations of fieldenum.New, fieldenum.TryNew, fieldenum.Shared
+// and fieldenum.Explain in type-checked files,
+// and checks fieldenum struct tags of their type arguments by [fieldenum.Check],
+// so that invalid tags are reported at build time instead of panicking at runtime.
+package check
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"github.com/QAQandOwO/godget/fieldenum"
+)
+
+const (
+	fieldenumPath = "github.com/QAQandOwO/godget/fieldenum"
+	enumPath      = "github.com/QAQandOwO/godget/enum"
+)
+
+// checkedFuncs are the functions of package fieldenum whose type arguments are checked.
+var checkedFuncs = map[string]bool{"New": true, "TryNew": true, "Shared": true, "Explain": true}
+
+// Diagnostic is an error of a fieldenum struct tag located in source files.
+// Message is a single line, and Snippet renders the tag with an underline under the invalid part.
+type Diagnostic struct {
+	Pos     token.Pos
+	End     token.Pos
+	Message string
+	Snippet string
+}
+
+// basicTypes maps basic kinds of field types to reflect types.
+var basicTypes = map[types.BasicKind]reflect.Type{
+	types.Int:        reflect.TypeOf(int(0)),
+	types.Int8:       reflect.TypeOf(int8(0)),
+	types.Int16:      reflect.TypeOf(int16(0)),
+	types.Int32:      reflect.TypeOf(int32(0)),
+	types.Int64:      reflect.TypeOf(int64(0)),
+	types.Uint:       reflect.TypeOf(uint(0)),
+	types.Uint8:      reflect.TypeOf(uint8(0)),
+	types.Uint16:     reflect.TypeOf(uint16(0)),
+	types.Uint32:     reflect.TypeOf(uint32(0)),
+	types.Uint64:     reflect.TypeOf(uint64(0)),
+	types.Uintptr:    reflect.TypeOf(uintptr(0)),
+	types.Float32:    reflect.TypeOf(float32(0)),
+	types.Float64:    reflect.TypeOf(float64(0)),
+	types.Complex64:  reflect.TypeOf(complex64(0)),
+	types.Complex128: reflect.TypeOf(complex128(0)),
+	types.String:     reflect.TypeOf(""),
+}
+
+// checker holds the state of checking files.
+type checker struct {
+	info    *types.Info
+	tags    map[token.Pos]*ast.BasicLit // tags of struct fields by field positions
+	checked map[string]bool
+	diags   []Diagnostic
+}
+
+// Files checks fieldenum.New, fieldenum.TryNew, fieldenum.Shared and fieldenum.Explain calls in files.
+// Info must record Uses and Instances of files.
+// If a call has options, only syntax errors of expressions and wrong argument counts
+// of built-in functions are reported, since registered values and functions are unknown before running.
+func Files(files []*ast.File, info *types.Info) []Diagnostic {
+	c := &checker{info: info, tags: make(map[token.Pos]*ast.BasicLit), checked: make(map[string]bool)}
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			if st, ok := node.(*ast.StructType); ok {
+				for _, field := range st.Fields.List {
+					for _, name := range field.Names {
+						c.tags[name.Pos()] = field.Tag
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			if call, ok := node.(*ast.CallExpr); ok {
+				c.checkCall(call)
+			}
+			return true
+		})
+	}
+	return c.diags
+}
+
+func (c *checker) checkCall(call *ast.CallExpr) {
+	var ident *ast.Ident
+	switch fun := unparen(call.Fun).(type) {
+	case *ast.IndexExpr:
+		ident = funcIdent(fun.X)
+	case *ast.IndexListExpr:
+		ident = funcIdent(fun.X)
+	}
+	if ident == nil {
+		return
+	}
+	fn, ok := c.info.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != fieldenumPath || !checkedFuncs[fn.Name()] {
+		return
+	}
+	inst, ok := c.info.Instances[ident]
+	if !ok || inst.TypeArgs.Len() != 1 {
+		return
+	}
+
+	typ := inst.TypeArgs.At(0)
+	hasOptions, shared := len(call.Args) > 0, fn.Name() == "Shared"
+	key := fmt.Sprintf("%s %t %t", types.TypeString(typ, nil), hasOptions, shared)
+	if c.checked[key] {
+		return
+	}
+	c.checked[key] = true
+	c.checkType(call, typ, hasOptions, shared)
+}
+
+func unparen(expr ast.Expr) ast.Expr {
+	for {
+		paren, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = paren.X
+	}
+}
+
+func funcIdent(expr ast.Expr) *ast.Ident {
+	switch x := unparen(expr).(type) {
+	case *ast.Ident:
+		return x
+	case *ast.SelectorExpr:
+		return x.Sel
+	}
+	return nil
+}
+
+// checkType converts the struct type to a reflect type with the same field kinds and tags,
+// and checks it by fieldenum.Check. Types of fieldenum.Shared must not have enum.Enum fields.
+func (c *checker) checkType(call *ast.CallExpr, typ types.Type, hasOptions, shared bool) {
+	name := typeName(typ)
+	st, ok := typ.Underlying().(*types.Struct)
+	if ptr, isPtr := typ.Underlying().(*types.Pointer); isPtr {
+		st, ok = ptr.Elem().Underlying().(*types.Struct)
+	}
+	if !ok {
+		c.report(call.Pos(), call.End(), fmt.Sprintf(`fieldenum: invalid type "%s"`, types.TypeString(typ, nil)), "")
+		return
+	}
+
+	fields := make([]reflect.StructField, st.NumFields())
+	for i := range fields {
+		v := st.Field(i)
+		if !v.Exported() {
+			c.reportField(call, v, name, "is not settable")
+			return
+		}
+		rt := ReflectType(v.Type())
+		if rt == nil {
+			c.reportField(call, v, name, fmt.Sprintf(`invalid type "%s"`, types.TypeString(v.Type(), nil)))
+			return
+		}
+		fields[i] = reflect.StructField{Name: v.Name(), Type: rt, Tag: reflect.StructTag(st.Tag(i))}
+	}
+	if v, groupName := enumField(st, name); shared && v != nil {
+		c.reportField(call, v, groupName, "enum.Enum fields can't be shared, use New instead")
+	}
+
+	err := fieldenum.Check(reflect.StructOf(fields))
+	var errs fieldenum.Errors
+	if !errors.As(err, &errs) {
+		if err != nil {
+			c.report(call.Pos(), call.End(), "fieldenum: "+err.Error(), "")
+		}
+		return
+	}
+	for _, err := range errs {
+		var fieldErr *fieldenum.FieldError
+		if !errors.As(err, &fieldErr) {
+			continue
+		}
+		if hasOptions && !optionsFree(fieldErr) {
+			continue
+		}
+		c.reportTag(call, fieldVar(st, fieldErr.Name), fieldErr, name)
+	}
+}
+
+// enumField returns the first field of type enum.Enum in st or its groups,
+// and the qualified name of the struct of the field.
+func enumField(st *types.Struct, name string) (*types.Var, string) {
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		if IsEnum(v.Type()) {
+			return v, name
+		}
+		if group, ok := v.Type().Underlying().(*types.Struct); ok {
+			if enumVar, groupName := enumField(group, name+"."+v.Name()); enumVar != nil {
+				return enumVar, groupName
+			}
+		}
+	}
+	return nil, ""
+}
+
+// optionsFree reports whether the error of a field can't be fixed by options,
+// i.e. it's a syntax error or a wrong argument count of a built-in function which can't be replaced.
+func optionsFree(fieldErr *fieldenum.FieldError) bool {
+	exprErr, ok := fieldErr.Err.(*fieldenum.ExprError)
+	if !ok {
+		return false
+	}
+	if exprErr.Err == nil {
+		return true
+	}
+	var funcErr *fieldenum.FuncError
+	if !errors.As(exprErr, &funcErr) || funcErr.NumRange == nil {
+		return false
+	}
+	return fieldenum.ReservedFunc(funcErr.Func)
+}
+
+// fieldVar returns the field of st named by the qualified name of a field error,
+// e.g. "Auth.Expired" or "Flags[2]", or nil if not found.
+func fieldVar(st *types.Struct, name string) *types.Var {
+	var v *types.Var
+	for _, part := range strings.Split(name, ".") {
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			part = part[:i]
+		}
+		if st == nil {
+			return nil
+		}
+		v = nil
+		for i := 0; i < st.NumFields(); i++ {
+			if st.Field(i).Name() == part {
+				v = st.Field(i)
+			}
+		}
+		if v == nil {
+			return nil
+		}
+		st, _ = v.Type().Underlying().(*types.Struct)
+	}
+	return v
+}
+
+// ReflectType returns the reflect type with the same field kind as t, or nil if t is an invalid field type.
+// [enum.Enum] fields are converted to int fields, which have the same assignment rules.
+// Group fields of struct types and array fields are converted recursively with their struct tags.
+func ReflectType(t types.Type) reflect.Type {
+	if IsEnum(t) {
+		return basicTypes[types.Int]
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return basicTypes[u.Kind()]
+	case *types.Array:
+		if elem := ReflectType(u.Elem()); elem != nil {
+			return reflect.ArrayOf(int(u.Len()), elem)
+		}
+	case *types.Struct:
+		fields := make([]reflect.StructField, u.NumFields())
+		for i := range fields {
+			v := u.Field(i)
+			rt := ReflectType(v.Type())
+			// reflect.StructOf doesn't allow unexported fields
+			if rt == nil || !v.Exported() {
+				return nil
+			}
+			fields[i] = reflect.StructField{Name: v.Name(), Type: rt, Tag: reflect.StructTag(u.Tag(i))}
+		}
+		return reflect.StructOf(fields)
+	}
+	return nil
+}
+
+// IsEnum reports whether t is an instance of [enum.Enum].
+func IsEnum(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == enumPath && obj.Name() == "Enum"
+}
+
+func typeName(t types.Type) string {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return "struct{...}"
+}
+
+func (c *checker) reportField(call *ast.CallExpr, v *types.Var, typeName, msg string) {
+	pos, end := call.Pos(), call.End()
+	if tag, ok := c.tags[v.Pos()]; ok && tag != nil {
+		pos, end = tag.Pos(), tag.End()
+	} else if ok {
+		pos, end = v.Pos(), v.Pos()+token.Pos(len(v.Name()))
+	}
+	c.report(pos, end, fmt.Sprintf("fieldenum: %s.%s: %s", typeName, v.Name(), msg), "")
+}
+
+// reportTag reports an error of a field tag, located at the invalid part of the tag if possible.
+func (c *checker) reportTag(call *ast.CallExpr, v *types.Var, fieldErr *fieldenum.FieldError, typeName string) {
+	// render the diagnostic without the reflect type, which is converted from the source type
+	fieldErr.Type = nil
+	lines := strings.SplitN(fieldErr.Diagnostic(), "\n", 2)
+	msg := fmt.Sprintf("fieldenum: %s.%s", typeName, lines[0])
+	var snippet string
+	if len(lines) > 1 {
+		snippet = lines[1]
+	}
+
+	var tag *ast.BasicLit
+	if v != nil {
+		tag = c.tags[v.Pos()]
+	}
+	if tag == nil {
+		c.report(call.Pos(), call.End(), msg, snippet)
+		return
+	}
+	pos, end := tag.Pos(), tag.End()
+	// columns can be mapped to the source only if the tag is a raw string without escapes
+	const key = `fieldenum:"`
+	if i := strings.Index(tag.Value, key); i >= 0 && strings.HasPrefix(tag.Value, "`") && fieldErr.Tag != "" &&
+		strings.HasPrefix(tag.Value[i+len(key):], fieldErr.Tag+`"`) && !strings.Contains(fieldErr.Tag, `\`) {
+		start := tag.Pos() + token.Pos(i+len(key))
+		pos, end = start+token.Pos(fieldErr.Start-1), start+token.Pos(fieldErr.End-1)
+	}
+	c.report(pos, end, msg, snippet)
+}
+
+func (c *checker) report(pos, end token.Pos, msg, snippet string) {
+	c.diags = append(c.diags, Diagnostic{Pos: pos, End: end, Message: msg, Snippet: snippet})
+}
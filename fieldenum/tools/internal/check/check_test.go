@@ -0,0 +1,84 @@
+package check
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+const src = `package p
+
+import (
+	"github.com/QAQandOwO/godget/enum"
+	"github.com/QAQandOwO/godget/fieldenum"
+)
+
+type Limits struct {
+	A int8              ` + "`fieldenum:\"200\"`" + `
+	B int               ` + "`fieldenum:\"1 + foo(2)\"`" + `
+	C enum.Enum[Limits] ` + "`fieldenum:\"x\"`" + `
+}
+
+var (
+	_    = fieldenum.New[Limits]()
+	_    = fieldenum.New[Limits]()
+	_, _ = fieldenum.TryNew[struct{ X int ` + "`fieldenum:\"y\"`" + ` }](fieldenum.WithValues(nil))
+	_    = fieldenum.New[struct{ X int ` + "`fieldenum:\"(\"`" + ` }](fieldenum.WithValues(nil))
+	_    = fieldenum.New[struct{ X int ` + "`fieldenum:\"max() + foo(1)\"`" + ` }](fieldenum.WithValues(nil))
+	_    = fieldenum.New[struct{ x int }]()
+	_    = fieldenum.New[int]()
+	_    = fieldenum.New[struct{ X int }]()
+	_    = fieldenum.New[struct{ G struct{ X [2]int8 ` + "`fieldenum:\"iota + foo(2)\"`" + ` } }]()
+	_    = fieldenum.Shared[struct{ X int ` + "`fieldenum:\"foo()\"`" + ` }]()
+	_, _ = fieldenum.Explain[struct{ X uint8 ` + "`fieldenum:\"-1\"`" + ` }]()
+	_    = fieldenum.Shared[struct{ G struct{ L enum.Enum[Limits] } }]()
+	_    = fieldenum.New[struct{ X int ` + "`fieldenum:\"floor()\"`" + ` }](fieldenum.WithValues(nil))
+)
+`
+
+func TestFiles(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Uses:      make(map[*ast.Ident]types.Object),
+		Instances: make(map[*ast.Ident]types.Instance),
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err = conf.Check("p", fset, []*ast.File{file}, info); err != nil {
+		t.Fatal(err)
+	}
+
+	wants := []struct {
+		pos     string
+		message string
+		snippet string
+	}{
+		0:  {pos: "p.go:9:22", message: `fieldenum: Limits.A: value "200" overflows type int8`},
+		1:  {pos: "p.go:10:38", message: `fieldenum: Limits.B: call non-existed function "foo"`, snippet: "\t1 + foo(2)\n\t    ^~~~~~"},
+		2:  {pos: "p.go:11:34", message: `fieldenum: Limits.C: unsupported identifier "x"`, snippet: "\tx\n\t^"},
+		3:  {pos: "p.go:18:49", message: `fieldenum: struct{...}.X: invalid expression`, snippet: "\t(\n\t^"},
+		4:  {pos: "p.go:19:49", message: `fieldenum: struct{...}.X: call function max on too few arguments`, snippet: "\tmax() + foo(1)\n\t^~~~~"},
+		5:  {pos: "p.go:20:31", message: `fieldenum: struct{...}.x: is not settable`},
+		6:  {pos: "p.go:21:9", message: `fieldenum: invalid type "int"`},
+		7:  {pos: "p.go:23:70", message: `fieldenum: struct{...}.G.X[0]: call non-existed function "foo"`, snippet: "\tiota + foo(2)\n\t       ^~~~~~"},
+		8:  {pos: "p.go:24:52", message: `fieldenum: struct{...}.X: call non-existed function "foo"`, snippet: "\tfoo()\n\t^~~~~"},
+		9:  {pos: "p.go:25:43", message: `fieldenum: struct{...}.X: assgin negative value "-1" to type uint8`},
+		10: {pos: "p.go:26:44", message: `fieldenum: struct{...}.G.L: enum.Enum fields can't be shared, use New instead`},
+	}
+	diags := Files([]*ast.File{file}, info)
+	if len(diags) != len(wants) {
+		t.Fatalf("ERROR: got %d diagnostics %v, want %d diagnostics", len(diags), diags, len(wants))
+	}
+	for i, want := range wants {
+		diag := diags[i]
+		if pos := fset.Position(diag.Pos).String(); pos != want.pos || diag.Message != want.message || diag.Snippet != want.snippet {
+			t.Errorf("[%d]ERROR: got %s: %s %q, want %s: %s %q", i, pos, diag.Message, diag.Snippet, want.pos, want.message, want.snippet)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Package load type-checks packages from source for the fieldenum commands.
+package load
+
+import (
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+)
+
+// Package is a type-checked package.
+type Package struct {
+	Dir   string
+	Types *types.Package
+	Files []*ast.File
+	Info  *types.Info
+}
+
+// Dir type-checks the package in dir, imports are type-checked from source.
+// Build constraints are respected, and test files and files named in ignored aren't loaded.
+// It returns an error of type *build.NoGoError if dir has no Go files.
+func Dir(fset *token.FileSet, dir string, ignored ...string) (*Package, error) {
+	bpkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*ast.File, 0, len(bpkg.GoFiles))
+loop:
+	for _, name := range bpkg.GoFiles {
+		for _, ignoredName := range ignored {
+			if name == ignoredName {
+				continue loop
+			}
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	info := &types.Info{
+		Defs:      make(map[*ast.Ident]types.Object),
+		Uses:      make(map[*ast.Ident]types.Object),
+		Instances: make(map[*ast.Ident]types.Instance),
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check(bpkg.ImportPath, fset, files, info)
+	if err != nil {
+		return nil, err
+	}
+	return &Package{Dir: dir, Types: pkg, Files: files, Info: info}, nil
+}
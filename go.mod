@@ -2,4 +2,4 @@ module github.com/QAQandOwO/godget
 
 go 1.18
 
-retract [v0.1.0, v0.3.0]
\ No newline at end of file
+retract [v0.1.0, v0.3.0]